@@ -16,15 +16,39 @@ func (p *AstPrinter) Print(expr ast.Expr) (any, error) {
 	return expr.Accept(p)
 }
 
+func (p *AstPrinter) VisitAssignExpr(expr *ast.Assign) (any, error) {
+	return p.parenthesize("= "+expr.Name.Lexeme, expr.Value), nil
+}
+
 func (p *AstPrinter) VisitBinaryExpr(expr *ast.Binary) (any, error) {
 	return p.parenthesize(expr.Operator.Lexeme,
 		expr.Left, expr.Right), nil
 }
 
+func (p *AstPrinter) VisitCallExpr(expr *ast.Call) (any, error) {
+	return p.parenthesize("call", append([]ast.Expr{expr.Callee}, expr.Arguments...)...), nil
+}
+
+func (p *AstPrinter) VisitGetExpr(expr *ast.Get) (any, error) {
+	return p.parenthesize(". "+expr.Name.Lexeme, expr.Object), nil
+}
+
 func (p *AstPrinter) VisitGroupingExpr(expr *ast.Grouping) (any, error) {
 	return p.parenthesize("group", expr.Expression), nil
 }
 
+func (p *AstPrinter) VisitIndexExpr(expr *ast.Index) (any, error) {
+	return p.parenthesize("[]", expr.Object, expr.Index), nil
+}
+
+func (p *AstPrinter) VisitIndexSetExpr(expr *ast.IndexSet) (any, error) {
+	return p.parenthesize("[]=", expr.Object, expr.Index, expr.Value), nil
+}
+
+func (p *AstPrinter) VisitListLiteralExpr(expr *ast.ListLiteral) (any, error) {
+	return p.parenthesize("list", expr.Elements...), nil
+}
+
 func (p *AstPrinter) VisitLiteralExpr(expr *ast.Literal) (any, error) {
 	if expr.Value == nil {
 		return "nil", nil
@@ -32,10 +56,43 @@ func (p *AstPrinter) VisitLiteralExpr(expr *ast.Literal) (any, error) {
 	return expr.Value, nil
 }
 
+func (p *AstPrinter) VisitLogicalExpr(expr *ast.Logical) (any, error) {
+	return p.parenthesize(expr.Operator.Lexeme,
+		expr.Left, expr.Right), nil
+}
+
+func (p *AstPrinter) VisitMapLiteralExpr(expr *ast.MapLiteral) (any, error) {
+	entries := make([]ast.Expr, 0, 2*len(expr.Keys))
+	for i, key := range expr.Keys {
+		entries = append(entries, key, expr.Values[i])
+	}
+	return p.parenthesize("map", entries...), nil
+}
+
+func (p *AstPrinter) VisitSetExpr(expr *ast.Set) (any, error) {
+	return p.parenthesize("= "+expr.Name.Lexeme, expr.Object, expr.Value), nil
+}
+
+func (p *AstPrinter) VisitSliceExpr(expr *ast.Slice) (any, error) {
+	return p.parenthesize("[:]", expr.Object, expr.Start, expr.End), nil
+}
+
+func (p *AstPrinter) VisitSuperExpr(expr *ast.Super) (any, error) {
+	return "(super " + expr.Method.Lexeme + ")", nil
+}
+
+func (p *AstPrinter) VisitThisExpr(expr *ast.This) (any, error) {
+	return "this", nil
+}
+
 func (p *AstPrinter) VisitUnaryExpr(expr *ast.Unary) (any, error) {
 	return p.parenthesize(expr.Operator.Lexeme, expr.Right), nil
 }
 
+func (p *AstPrinter) VisitVariableExpr(expr *ast.Variable) (any, error) {
+	return expr.Name.Lexeme, nil
+}
+
 func (p *AstPrinter) parenthesize(name string, exprs ...ast.Expr) string {
 	builder := strings.Builder{}
 	builder.WriteString("(")
@@ -4,13 +4,45 @@ import "github.com/anwprath/glox/token"
 
 type Expr interface {
 	Accept(v ExprVisitor) (any, error)
+	Span() token.Span
 }
 
 type ExprVisitor interface {
+	VisitAssignExpr(expr *Assign) (any, error)
 	VisitBinaryExpr(expr *Binary) (any, error)
+	VisitCallExpr(expr *Call) (any, error)
+	VisitGetExpr(expr *Get) (any, error)
 	VisitGroupingExpr(expr *Grouping) (any, error)
+	VisitIndexExpr(expr *Index) (any, error)
+	VisitIndexSetExpr(expr *IndexSet) (any, error)
+	VisitListLiteralExpr(expr *ListLiteral) (any, error)
 	VisitLiteralExpr(expr *Literal) (any, error)
+	VisitLogicalExpr(expr *Logical) (any, error)
+	VisitMapLiteralExpr(expr *MapLiteral) (any, error)
+	VisitSetExpr(expr *Set) (any, error)
+	VisitSliceExpr(expr *Slice) (any, error)
+	VisitSuperExpr(expr *Super) (any, error)
+	VisitThisExpr(expr *This) (any, error)
 	VisitUnaryExpr(expr *Unary) (any, error)
+	VisitVariableExpr(expr *Variable) (any, error)
+}
+
+type Assign struct {
+	Name  token.Token
+	Value Expr
+}
+
+func (node *Assign) Accept(v ExprVisitor) (any, error) {
+	return v.VisitAssignExpr(node)
+}
+
+func (node *Assign) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Name.Span)
+	if node.Value != nil {
+		span = span.Merge(node.Value.Span())
+	}
+	return span
 }
 
 type Binary struct {
@@ -23,6 +55,58 @@ func (node *Binary) Accept(v ExprVisitor) (any, error) {
 	return v.VisitBinaryExpr(node)
 }
 
+func (node *Binary) Span() token.Span {
+	var span token.Span
+	if node.Left != nil {
+		span = span.Merge(node.Left.Span())
+	}
+	span = span.Merge(node.Operator.Span)
+	if node.Right != nil {
+		span = span.Merge(node.Right.Span())
+	}
+	return span
+}
+
+type Call struct {
+	Callee    Expr
+	Paren     token.Token
+	Arguments []Expr
+}
+
+func (node *Call) Accept(v ExprVisitor) (any, error) {
+	return v.VisitCallExpr(node)
+}
+
+func (node *Call) Span() token.Span {
+	var span token.Span
+	if node.Callee != nil {
+		span = span.Merge(node.Callee.Span())
+	}
+	span = span.Merge(node.Paren.Span)
+	for _, n := range node.Arguments {
+		span = span.Merge(n.Span())
+	}
+	return span
+}
+
+type Get struct {
+	Object Expr
+	Name   token.Token
+}
+
+func (node *Get) Accept(v ExprVisitor) (any, error) {
+	return v.VisitGetExpr(node)
+}
+
+func (node *Get) Span() token.Span {
+	var span token.Span
+	if node.Object != nil {
+		span = span.Merge(node.Object.Span())
+	}
+	span = span.Merge(node.Name.Span)
+	return span
+}
+
 type Grouping struct {
 	Expression Expr
 }
@@ -31,14 +115,217 @@ func (node *Grouping) Accept(v ExprVisitor) (any, error) {
 	return v.VisitGroupingExpr(node)
 }
 
+func (node *Grouping) Span() token.Span {
+	var span token.Span
+	if node.Expression != nil {
+		span = span.Merge(node.Expression.Span())
+	}
+	return span
+}
+
+type Index struct {
+	Object  Expr
+	Bracket token.Token
+	Index   Expr
+}
+
+func (node *Index) Accept(v ExprVisitor) (any, error) {
+	return v.VisitIndexExpr(node)
+}
+
+func (node *Index) Span() token.Span {
+	var span token.Span
+	if node.Object != nil {
+		span = span.Merge(node.Object.Span())
+	}
+	span = span.Merge(node.Bracket.Span)
+	if node.Index != nil {
+		span = span.Merge(node.Index.Span())
+	}
+	return span
+}
+
+type IndexSet struct {
+	Object  Expr
+	Bracket token.Token
+	Index   Expr
+	Value   Expr
+}
+
+func (node *IndexSet) Accept(v ExprVisitor) (any, error) {
+	return v.VisitIndexSetExpr(node)
+}
+
+func (node *IndexSet) Span() token.Span {
+	var span token.Span
+	if node.Object != nil {
+		span = span.Merge(node.Object.Span())
+	}
+	span = span.Merge(node.Bracket.Span)
+	if node.Index != nil {
+		span = span.Merge(node.Index.Span())
+	}
+	if node.Value != nil {
+		span = span.Merge(node.Value.Span())
+	}
+	return span
+}
+
+type ListLiteral struct {
+	Bracket  token.Token
+	Elements []Expr
+}
+
+func (node *ListLiteral) Accept(v ExprVisitor) (any, error) {
+	return v.VisitListLiteralExpr(node)
+}
+
+func (node *ListLiteral) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Bracket.Span)
+	for _, n := range node.Elements {
+		span = span.Merge(n.Span())
+	}
+	return span
+}
+
 type Literal struct {
 	Value any
+	Token token.Token
 }
 
 func (node *Literal) Accept(v ExprVisitor) (any, error) {
 	return v.VisitLiteralExpr(node)
 }
 
+func (node *Literal) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Token.Span)
+	return span
+}
+
+type Logical struct {
+	Left     Expr
+	Operator token.Token
+	Right    Expr
+}
+
+func (node *Logical) Accept(v ExprVisitor) (any, error) {
+	return v.VisitLogicalExpr(node)
+}
+
+func (node *Logical) Span() token.Span {
+	var span token.Span
+	if node.Left != nil {
+		span = span.Merge(node.Left.Span())
+	}
+	span = span.Merge(node.Operator.Span)
+	if node.Right != nil {
+		span = span.Merge(node.Right.Span())
+	}
+	return span
+}
+
+type MapLiteral struct {
+	Brace  token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+func (node *MapLiteral) Accept(v ExprVisitor) (any, error) {
+	return v.VisitMapLiteralExpr(node)
+}
+
+func (node *MapLiteral) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Brace.Span)
+	for _, n := range node.Keys {
+		span = span.Merge(n.Span())
+	}
+	for _, n := range node.Values {
+		span = span.Merge(n.Span())
+	}
+	return span
+}
+
+type Set struct {
+	Object Expr
+	Name   token.Token
+	Value  Expr
+}
+
+func (node *Set) Accept(v ExprVisitor) (any, error) {
+	return v.VisitSetExpr(node)
+}
+
+func (node *Set) Span() token.Span {
+	var span token.Span
+	if node.Object != nil {
+		span = span.Merge(node.Object.Span())
+	}
+	span = span.Merge(node.Name.Span)
+	if node.Value != nil {
+		span = span.Merge(node.Value.Span())
+	}
+	return span
+}
+
+type Slice struct {
+	Object  Expr
+	Bracket token.Token
+	Start   Expr
+	End     Expr
+}
+
+func (node *Slice) Accept(v ExprVisitor) (any, error) {
+	return v.VisitSliceExpr(node)
+}
+
+func (node *Slice) Span() token.Span {
+	var span token.Span
+	if node.Object != nil {
+		span = span.Merge(node.Object.Span())
+	}
+	span = span.Merge(node.Bracket.Span)
+	if node.Start != nil {
+		span = span.Merge(node.Start.Span())
+	}
+	if node.End != nil {
+		span = span.Merge(node.End.Span())
+	}
+	return span
+}
+
+type Super struct {
+	Keyword token.Token
+	Method  token.Token
+}
+
+func (node *Super) Accept(v ExprVisitor) (any, error) {
+	return v.VisitSuperExpr(node)
+}
+
+func (node *Super) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Keyword.Span)
+	span = span.Merge(node.Method.Span)
+	return span
+}
+
+type This struct {
+	Keyword token.Token
+}
+
+func (node *This) Accept(v ExprVisitor) (any, error) {
+	return v.VisitThisExpr(node)
+}
+
+func (node *This) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Keyword.Span)
+	return span
+}
+
 type Unary struct {
 	Operator token.Token
 	Right    Expr
@@ -47,3 +334,26 @@ type Unary struct {
 func (node *Unary) Accept(v ExprVisitor) (any, error) {
 	return v.VisitUnaryExpr(node)
 }
+
+func (node *Unary) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Operator.Span)
+	if node.Right != nil {
+		span = span.Merge(node.Right.Span())
+	}
+	return span
+}
+
+type Variable struct {
+	Name token.Token
+}
+
+func (node *Variable) Accept(v ExprVisitor) (any, error) {
+	return v.VisitVariableExpr(node)
+}
+
+func (node *Variable) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Name.Span)
+	return span
+}
@@ -0,0 +1,194 @@
+package ast
+
+import "github.com/anwprath/glox/token"
+
+type Stmt interface {
+	Accept(v StmtVisitor) (any, error)
+	Span() token.Span
+}
+
+type StmtVisitor interface {
+	VisitBlockStmt(stmt *Block) (any, error)
+	VisitClassStmt(stmt *Class) (any, error)
+	VisitExpressionStmt(stmt *Expression) (any, error)
+	VisitFunctionStmt(stmt *Function) (any, error)
+	VisitIfStmt(stmt *If) (any, error)
+	VisitPrintStmt(stmt *Print) (any, error)
+	VisitReturnStmt(stmt *Return) (any, error)
+	VisitVarStmt(stmt *Var) (any, error)
+	VisitWhileStmt(stmt *While) (any, error)
+}
+
+type Block struct {
+	Statements []Stmt
+}
+
+func (node *Block) Accept(v StmtVisitor) (any, error) {
+	return v.VisitBlockStmt(node)
+}
+
+func (node *Block) Span() token.Span {
+	var span token.Span
+	for _, n := range node.Statements {
+		span = span.Merge(n.Span())
+	}
+	return span
+}
+
+type Class struct {
+	Name       token.Token
+	Superclass *Variable
+	Methods    []*Function
+}
+
+func (node *Class) Accept(v StmtVisitor) (any, error) {
+	return v.VisitClassStmt(node)
+}
+
+func (node *Class) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Name.Span)
+	if node.Superclass != nil {
+		span = span.Merge(node.Superclass.Span())
+	}
+	for _, n := range node.Methods {
+		span = span.Merge(n.Span())
+	}
+	return span
+}
+
+type Expression struct {
+	Expression Expr
+}
+
+func (node *Expression) Accept(v StmtVisitor) (any, error) {
+	return v.VisitExpressionStmt(node)
+}
+
+func (node *Expression) Span() token.Span {
+	var span token.Span
+	if node.Expression != nil {
+		span = span.Merge(node.Expression.Span())
+	}
+	return span
+}
+
+type Function struct {
+	Name   token.Token
+	Params []token.Token
+	Body   []Stmt
+}
+
+func (node *Function) Accept(v StmtVisitor) (any, error) {
+	return v.VisitFunctionStmt(node)
+}
+
+func (node *Function) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Name.Span)
+	for _, t := range node.Params {
+		span = span.Merge(t.Span)
+	}
+	for _, n := range node.Body {
+		span = span.Merge(n.Span())
+	}
+	return span
+}
+
+type If struct {
+	Condition  Expr
+	ThenBranch Stmt
+	ElseBranch Stmt
+}
+
+func (node *If) Accept(v StmtVisitor) (any, error) {
+	return v.VisitIfStmt(node)
+}
+
+func (node *If) Span() token.Span {
+	var span token.Span
+	if node.Condition != nil {
+		span = span.Merge(node.Condition.Span())
+	}
+	if node.ThenBranch != nil {
+		span = span.Merge(node.ThenBranch.Span())
+	}
+	if node.ElseBranch != nil {
+		span = span.Merge(node.ElseBranch.Span())
+	}
+	return span
+}
+
+type Print struct {
+	Expression Expr
+}
+
+func (node *Print) Accept(v StmtVisitor) (any, error) {
+	return v.VisitPrintStmt(node)
+}
+
+func (node *Print) Span() token.Span {
+	var span token.Span
+	if node.Expression != nil {
+		span = span.Merge(node.Expression.Span())
+	}
+	return span
+}
+
+type Return struct {
+	Keyword token.Token
+	Value   Expr
+}
+
+func (node *Return) Accept(v StmtVisitor) (any, error) {
+	return v.VisitReturnStmt(node)
+}
+
+func (node *Return) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Keyword.Span)
+	if node.Value != nil {
+		span = span.Merge(node.Value.Span())
+	}
+	return span
+}
+
+type Var struct {
+	Name        token.Token
+	Initializer Expr
+}
+
+func (node *Var) Accept(v StmtVisitor) (any, error) {
+	return v.VisitVarStmt(node)
+}
+
+func (node *Var) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Name.Span)
+	if node.Initializer != nil {
+		span = span.Merge(node.Initializer.Span())
+	}
+	return span
+}
+
+type While struct {
+	Keyword   token.Token
+	Condition Expr
+	Body      Stmt
+}
+
+func (node *While) Accept(v StmtVisitor) (any, error) {
+	return v.VisitWhileStmt(node)
+}
+
+func (node *While) Span() token.Span {
+	var span token.Span
+	span = span.Merge(node.Keyword.Span)
+	if node.Condition != nil {
+		span = span.Merge(node.Condition.Span())
+	}
+	if node.Body != nil {
+		span = span.Merge(node.Body.Span())
+	}
+	return span
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"github.com/anwprath/glox/ast"
+	"github.com/anwprath/glox/compiler"
+	errors "github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/interpreter"
+	"github.com/anwprath/glox/optimizer"
+	"github.com/anwprath/glox/parser"
+	"github.com/anwprath/glox/resolver"
+	"github.com/anwprath/glox/scanner"
+	"github.com/anwprath/glox/source"
+	"github.com/anwprath/glox/stdlib"
+	"github.com/anwprath/glox/vm"
+)
+
+var Files = source.NewFileSet()
+var Reporter = errors.NewTerminalReporter(os.Stdout, Files)
+var Interpreter = interpreter.New(Reporter)
+var VM = vm.New(Reporter)
+
+var backend = flag.String("backend", "tree", "execution backend: tree (tree-walking interpreter) or vm (bytecode)")
+var optimize = flag.Bool("optimize", false, "fold constants and drop dead branches before running")
+var seed = flag.Uint64("seed", 0, "seed for random(); 0 picks one at random")
+var sandbox = flag.Bool("sandbox", false, "run without file system, environment, clock or process built-ins")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: glox [-backend=tree|vm] [-optimize] [-sandbox] [-seed=n] [script]\n       glox [-optimize] disasm script")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+
+	if *backend != "tree" && *backend != "vm" {
+		flag.Usage()
+		os.Exit(64)
+	}
+
+	caps := stdlib.Full()
+	if *sandbox {
+		caps = stdlib.Capabilities{}
+	}
+	if *seed != 0 {
+		caps.Random = rand.NewPCG(*seed, *seed)
+	}
+	stdlib.Install(Interpreter, caps)
+	stdlib.Install(VM, caps)
+
+	if len(args) == 2 && args[0] == "disasm" {
+		disasmFile(args[1])
+	} else if len(args) > 1 {
+		flag.Usage()
+		os.Exit(64)
+	} else if len(args) == 1 {
+		runFile(args[0])
+	} else {
+		runPrompt()
+	}
+}
+
+// parse scans, parses and resolves command. It returns false if any static
+// error was reported.
+func parse(name, command string) ([]ast.Stmt, bool) {
+	sc := scanner.New(Files, name, command, Reporter)
+	tokens := sc.ScanTokens()
+	tokenParser := parser.New(tokens, Reporter)
+	stmts, err := tokenParser.Parse()
+	if err != nil || Reporter.HadError() {
+		return nil, false
+	}
+
+	// Only the tree-walker needs the bindings; the compiler allocates its
+	// own slots.
+	var locals resolver.Locals
+	if *backend == "tree" {
+		locals = Interpreter
+	}
+	if err := resolver.New(locals, Reporter).Resolve(stmts); err != nil {
+		return nil, false
+	}
+
+	// After resolving, so that errors in dead code are still reported.
+	if *optimize {
+		stmts = optimizer.New().Optimize(stmts)
+	}
+	return stmts, true
+}
+
+func run(name, command string) {
+	stmts, ok := parse(name, command)
+	if !ok {
+		return
+	}
+
+	if *backend == "vm" {
+		function, err := compiler.New(Reporter).Compile(stmts)
+		if err != nil {
+			return
+		}
+		VM.Interpret(function)
+		return
+	}
+
+	Interpreter.Interpret(stmts)
+}
+
+func readFile(path string) string {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		slog.Error("error reading file", "file", path, "error", err)
+		log.Fatal()
+	}
+	return string(bytes)
+}
+
+func runFile(path string) {
+	run(path, readFile(path))
+	if Reporter.HadError() {
+		os.Exit(69)
+	}
+	if Reporter.HadRuntimeError() {
+		os.Exit(70)
+	}
+}
+
+func disasmFile(path string) {
+	stmts, ok := parse(path, readFile(path))
+	if !ok {
+		os.Exit(69)
+	}
+	function, err := compiler.New(Reporter).Compile(stmts)
+	if err != nil {
+		os.Exit(69)
+	}
+	compiler.Disassemble(os.Stdout, function)
+}
+
+func runPrompt() {
+	inputScanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("> ")
+		// Ctrl+D works here
+		if !inputScanner.Scan() {
+			break
+		}
+		text := strings.TrimSpace(inputScanner.Text())
+		if text == "" {
+			break
+		}
+		run("<stdin>", inputScanner.Text())
+		Reporter.Reset()
+	}
+}
@@ -0,0 +1,166 @@
+package compiler
+
+import (
+	"github.com/anwprath/glox/token"
+)
+
+type OpCode byte
+
+const (
+	// OP_CONSTANT u16 pushes constant u16.
+	OP_CONSTANT OpCode = iota
+	OP_NIL
+	OP_TRUE
+	OP_FALSE
+	OP_POP
+	// OP_GET_LOCAL/OP_SET_LOCAL u8 address a stack slot of the current frame.
+	OP_GET_LOCAL
+	OP_SET_LOCAL
+	// Global and property ops take the u16 index of the name constant.
+	OP_GET_GLOBAL
+	OP_DEFINE_GLOBAL
+	OP_SET_GLOBAL
+	// OP_GET_UPVALUE/OP_SET_UPVALUE u8 address an upvalue of the closure.
+	OP_GET_UPVALUE
+	OP_SET_UPVALUE
+	OP_GET_PROPERTY
+	OP_SET_PROPERTY
+	OP_GET_SUPER
+	// OP_GET_INDEX replaces an object and an index with object[index].
+	OP_GET_INDEX
+	// OP_SET_INDEX replaces an object, an index and a value with the value,
+	// storing it at object[index].
+	OP_SET_INDEX
+	// OP_SLICE replaces an object and two bounds with object[start:end]; a
+	// nil bound is left out.
+	OP_SLICE
+	// OP_LIST u16 replaces the top u16 values with a list of them.
+	OP_LIST
+	// OP_MAP u16 replaces the top u16 key-value pairs with a map of them.
+	OP_MAP
+	OP_EQUAL
+	OP_NOT_EQUAL
+	OP_GREATER
+	OP_GREATER_EQUAL
+	OP_LESS
+	OP_LESS_EQUAL
+	OP_ADD
+	OP_SUBTRACT
+	OP_MULTIPLY
+	OP_DIVIDE
+	OP_NOT
+	OP_NEGATE
+	OP_PRINT
+	// Jumps take a u16 offset relative to the next instruction.
+	OP_JUMP
+	OP_JUMP_IF_FALSE
+	OP_LOOP
+	// OP_CALL u8 calls the value below its u8 arguments.
+	OP_CALL
+	// OP_CLOSURE u16 wraps function constant u16, followed by one
+	// (isLocal u8, index u8) pair per upvalue.
+	OP_CLOSURE
+	OP_CLOSE_UPVALUE
+	OP_RETURN
+	OP_CLASS
+	OP_INHERIT
+	OP_METHOD
+)
+
+var opNames = [...]string{
+	OP_CONSTANT:      "OP_CONSTANT",
+	OP_NIL:           "OP_NIL",
+	OP_TRUE:          "OP_TRUE",
+	OP_FALSE:         "OP_FALSE",
+	OP_POP:           "OP_POP",
+	OP_GET_LOCAL:     "OP_GET_LOCAL",
+	OP_SET_LOCAL:     "OP_SET_LOCAL",
+	OP_GET_GLOBAL:    "OP_GET_GLOBAL",
+	OP_DEFINE_GLOBAL: "OP_DEFINE_GLOBAL",
+	OP_SET_GLOBAL:    "OP_SET_GLOBAL",
+	OP_GET_UPVALUE:   "OP_GET_UPVALUE",
+	OP_SET_UPVALUE:   "OP_SET_UPVALUE",
+	OP_GET_PROPERTY:  "OP_GET_PROPERTY",
+	OP_SET_PROPERTY:  "OP_SET_PROPERTY",
+	OP_GET_SUPER:     "OP_GET_SUPER",
+	OP_GET_INDEX:     "OP_GET_INDEX",
+	OP_SET_INDEX:     "OP_SET_INDEX",
+	OP_SLICE:         "OP_SLICE",
+	OP_LIST:          "OP_LIST",
+	OP_MAP:           "OP_MAP",
+	OP_EQUAL:         "OP_EQUAL",
+	OP_NOT_EQUAL:     "OP_NOT_EQUAL",
+	OP_GREATER:       "OP_GREATER",
+	OP_GREATER_EQUAL: "OP_GREATER_EQUAL",
+	OP_LESS:          "OP_LESS",
+	OP_LESS_EQUAL:    "OP_LESS_EQUAL",
+	OP_ADD:           "OP_ADD",
+	OP_SUBTRACT:      "OP_SUBTRACT",
+	OP_MULTIPLY:      "OP_MULTIPLY",
+	OP_DIVIDE:        "OP_DIVIDE",
+	OP_NOT:           "OP_NOT",
+	OP_NEGATE:        "OP_NEGATE",
+	OP_PRINT:         "OP_PRINT",
+	OP_JUMP:          "OP_JUMP",
+	OP_JUMP_IF_FALSE: "OP_JUMP_IF_FALSE",
+	OP_LOOP:          "OP_LOOP",
+	OP_CALL:          "OP_CALL",
+	OP_CLOSURE:       "OP_CLOSURE",
+	OP_CLOSE_UPVALUE: "OP_CLOSE_UPVALUE",
+	OP_RETURN:        "OP_RETURN",
+	OP_CLASS:         "OP_CLASS",
+	OP_INHERIT:       "OP_INHERIT",
+	OP_METHOD:        "OP_METHOD",
+}
+
+func (op OpCode) String() string {
+	if int(op) < len(opNames) && opNames[op] != "" {
+		return opNames[op]
+	}
+	return "OP_UNKNOWN"
+}
+
+// Chunk is a sequence of bytecode with its constant table. Tokens runs
+// parallel to Code and records the source token each byte was compiled
+// from, for line info and runtime errors.
+type Chunk struct {
+	Code      []byte
+	Constants []any
+	Tokens    []token.Token
+}
+
+func (c *Chunk) Write(b byte, t token.Token) {
+	c.Code = append(c.Code, b)
+	c.Tokens = append(c.Tokens, t)
+}
+
+// AddConstant appends value to the constant table and returns its index.
+func (c *Chunk) AddConstant(value any) int {
+	c.Constants = append(c.Constants, value)
+	return len(c.Constants) - 1
+}
+
+// Line returns the source line of the byte at offset.
+func (c *Chunk) Line(offset int) int {
+	return c.Tokens[offset].Line
+}
+
+func (c *Chunk) ReadShort(offset int) int {
+	return int(c.Code[offset])<<8 | int(c.Code[offset+1])
+}
+
+// Function is a compiled function body. The top-level script is a Function
+// with an empty Name.
+type Function struct {
+	Name         string
+	Arity        int
+	UpvalueCount int
+	Chunk        *Chunk
+}
+
+func (f *Function) String() string {
+	if f.Name == "" {
+		return "<script>"
+	}
+	return "<fn " + f.Name + ">"
+}
@@ -0,0 +1,652 @@
+package compiler
+
+import (
+	"math"
+
+	"github.com/anwprath/glox/ast"
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/token"
+)
+
+var _ ast.ExprVisitor = &Compiler{}
+var _ ast.StmtVisitor = &Compiler{}
+
+const (
+	maxLocals    = math.MaxUint8 + 1
+	maxUpvalues  = math.MaxUint8 + 1
+	maxConstants = math.MaxUint16 + 1
+	maxJump      = math.MaxUint16
+	maxElements  = math.MaxUint16
+)
+
+type functionType int
+
+const (
+	typeScript functionType = iota
+	typeFunction
+	typeMethod
+	typeInitializer
+)
+
+type local struct {
+	name       string
+	depth      int
+	isCaptured bool
+}
+
+type upvalue struct {
+	index   int
+	isLocal bool
+}
+
+// functionState is the per-function part of the compiler. They form a
+// chain through enclosing while nested functions are compiled.
+type functionState struct {
+	enclosing  *functionState
+	function   *Function
+	kind       functionType
+	locals     []local
+	upvalues   []upvalue
+	scopeDepth int
+}
+
+// Compiler turns a resolved program into bytecode for the vm package. It
+// does its own slot allocation for locals and upvalues, so the resolver is
+// only needed for its static checks.
+type Compiler struct {
+	current *functionState
+	// token is the token bytes are currently attributed to.
+	token    token.Token
+	reporter errors.Reporter
+	hadError bool
+}
+
+func New(reporter errors.Reporter) *Compiler {
+	return &Compiler{reporter: reporter}
+}
+
+// Compile compiles stmts into the top-level script function.
+func (c *Compiler) Compile(stmts []ast.Stmt) (*Function, error) {
+	c.beginFunction("", typeScript)
+	for _, stmt := range stmts {
+		c.compileStmt(stmt)
+	}
+	function, _ := c.endFunction()
+
+	if c.hadError {
+		return nil, errors.CompileErr{}
+	}
+	return function, nil
+}
+
+func (c *Compiler) VisitBlockStmt(stmt *ast.Block) (any, error) {
+	c.beginScope()
+	for _, s := range stmt.Statements {
+		c.compileStmt(s)
+	}
+	c.endScope()
+	return nil, nil
+}
+
+func (c *Compiler) VisitClassStmt(stmt *ast.Class) (any, error) {
+	c.token = stmt.Name
+	nameConstant := c.identifierConstant(stmt.Name)
+	c.declareVariable(stmt.Name.Lexeme)
+
+	c.emitOpShort(OP_CLASS, nameConstant)
+	c.defineVariable(nameConstant)
+
+	if stmt.Superclass != nil {
+		c.compileExpr(stmt.Superclass)
+
+		c.beginScope()
+		c.addLocal("super")
+		c.markInitialized()
+
+		c.namedVariable(stmt.Name, false)
+		c.token = stmt.Superclass.Name
+		c.emitOp(OP_INHERIT)
+	}
+
+	c.namedVariable(stmt.Name, false)
+	for _, method := range stmt.Methods {
+		kind := typeMethod
+		if method.Name.Lexeme == "init" {
+			kind = typeInitializer
+		}
+		c.function(method, kind)
+		c.token = method.Name
+		c.emitOpShort(OP_METHOD, c.identifierConstant(method.Name))
+	}
+	c.emitOp(OP_POP)
+
+	if stmt.Superclass != nil {
+		c.endScope()
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitExpressionStmt(stmt *ast.Expression) (any, error) {
+	c.compileExpr(stmt.Expression)
+	c.emitOp(OP_POP)
+	return nil, nil
+}
+
+func (c *Compiler) VisitFunctionStmt(stmt *ast.Function) (any, error) {
+	c.token = stmt.Name
+	nameConstant := c.identifierConstant(stmt.Name)
+	c.declareVariable(stmt.Name.Lexeme)
+	// Initialized before the body so the function can call itself.
+	c.markInitialized()
+	c.function(stmt, typeFunction)
+	c.defineVariable(nameConstant)
+	return nil, nil
+}
+
+func (c *Compiler) VisitIfStmt(stmt *ast.If) (any, error) {
+	c.compileExpr(stmt.Condition)
+
+	thenJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileStmt(stmt.ThenBranch)
+
+	elseJump := c.emitJump(OP_JUMP)
+	c.patchJump(thenJump)
+	c.emitOp(OP_POP)
+	if stmt.ElseBranch != nil {
+		c.compileStmt(stmt.ElseBranch)
+	}
+	c.patchJump(elseJump)
+	return nil, nil
+}
+
+func (c *Compiler) VisitPrintStmt(stmt *ast.Print) (any, error) {
+	c.compileExpr(stmt.Expression)
+	c.emitOp(OP_PRINT)
+	return nil, nil
+}
+
+func (c *Compiler) VisitReturnStmt(stmt *ast.Return) (any, error) {
+	c.token = stmt.Keyword
+	if stmt.Value == nil {
+		c.emitReturn()
+		return nil, nil
+	}
+
+	c.compileExpr(stmt.Value)
+	c.token = stmt.Keyword
+	c.emitOp(OP_RETURN)
+	return nil, nil
+}
+
+func (c *Compiler) VisitVarStmt(stmt *ast.Var) (any, error) {
+	c.token = stmt.Name
+	nameConstant := c.identifierConstant(stmt.Name)
+
+	if stmt.Initializer != nil {
+		c.compileExpr(stmt.Initializer)
+	} else {
+		c.token = stmt.Name
+		c.emitOp(OP_NIL)
+	}
+
+	// Declared after the initializer: the resolver already rejects a local
+	// that reads itself, and the value now sits exactly in the new slot.
+	c.declareVariable(stmt.Name.Lexeme)
+	c.defineVariable(nameConstant)
+	return nil, nil
+}
+
+func (c *Compiler) VisitWhileStmt(stmt *ast.While) (any, error) {
+	loopStart := len(c.chunk().Code)
+	c.compileExpr(stmt.Condition)
+
+	exitJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileStmt(stmt.Body)
+	c.token = stmt.Keyword
+	c.emitLoop(loopStart)
+
+	c.patchJump(exitJump)
+	c.emitOp(OP_POP)
+	return nil, nil
+}
+
+func (c *Compiler) VisitAssignExpr(expr *ast.Assign) (any, error) {
+	c.compileExpr(expr.Value)
+	c.namedVariable(expr.Name, true)
+	return nil, nil
+}
+
+func (c *Compiler) VisitBinaryExpr(expr *ast.Binary) (any, error) {
+	c.compileExpr(expr.Left)
+	c.compileExpr(expr.Right)
+
+	c.token = expr.Operator
+	switch expr.Operator.TokenType {
+	case token.EQUAL_EQUAL:
+		c.emitOp(OP_EQUAL)
+	case token.BANG_EQUAL:
+		c.emitOp(OP_NOT_EQUAL)
+	case token.GREATER:
+		c.emitOp(OP_GREATER)
+	case token.GREATER_EQUAL:
+		c.emitOp(OP_GREATER_EQUAL)
+	case token.LESS:
+		c.emitOp(OP_LESS)
+	case token.LESS_EQUAL:
+		c.emitOp(OP_LESS_EQUAL)
+	case token.PLUS:
+		c.emitOp(OP_ADD)
+	case token.MINUS:
+		c.emitOp(OP_SUBTRACT)
+	case token.STAR:
+		c.emitOp(OP_MULTIPLY)
+	case token.SLASH:
+		c.emitOp(OP_DIVIDE)
+	default:
+		panic("unreachable code in VisitBinaryExpr")
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitCallExpr(expr *ast.Call) (any, error) {
+	c.compileExpr(expr.Callee)
+	for _, arg := range expr.Arguments {
+		c.compileExpr(arg)
+	}
+
+	c.token = expr.Paren
+	c.emitBytes(byte(OP_CALL), byte(len(expr.Arguments)))
+	return nil, nil
+}
+
+func (c *Compiler) VisitGetExpr(expr *ast.Get) (any, error) {
+	c.compileExpr(expr.Object)
+	c.token = expr.Name
+	c.emitOpShort(OP_GET_PROPERTY, c.identifierConstant(expr.Name))
+	return nil, nil
+}
+
+func (c *Compiler) VisitGroupingExpr(expr *ast.Grouping) (any, error) {
+	c.compileExpr(expr.Expression)
+	return nil, nil
+}
+
+func (c *Compiler) VisitIndexExpr(expr *ast.Index) (any, error) {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Index)
+	c.token = expr.Bracket
+	c.emitOp(OP_GET_INDEX)
+	return nil, nil
+}
+
+func (c *Compiler) VisitIndexSetExpr(expr *ast.IndexSet) (any, error) {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Index)
+	c.compileExpr(expr.Value)
+	c.token = expr.Bracket
+	c.emitOp(OP_SET_INDEX)
+	return nil, nil
+}
+
+func (c *Compiler) VisitListLiteralExpr(expr *ast.ListLiteral) (any, error) {
+	for _, element := range expr.Elements {
+		c.compileExpr(element)
+	}
+	c.token = expr.Bracket
+	if len(expr.Elements) > maxElements {
+		c.error("too many elements in list literal.")
+	}
+	c.emitOpShort(OP_LIST, len(expr.Elements))
+	return nil, nil
+}
+
+func (c *Compiler) VisitLiteralExpr(expr *ast.Literal) (any, error) {
+	if !expr.Token.Span.IsZero() {
+		c.token = expr.Token
+	}
+	switch value := expr.Value.(type) {
+	case nil:
+		c.emitOp(OP_NIL)
+	case bool:
+		if value {
+			c.emitOp(OP_TRUE)
+		} else {
+			c.emitOp(OP_FALSE)
+		}
+	default:
+		c.emitOpShort(OP_CONSTANT, c.makeConstant(value))
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitLogicalExpr(expr *ast.Logical) (any, error) {
+	c.compileExpr(expr.Left)
+	c.token = expr.Operator
+
+	if expr.Operator.TokenType == token.AND {
+		endJump := c.emitJump(OP_JUMP_IF_FALSE)
+		c.emitOp(OP_POP)
+		c.compileExpr(expr.Right)
+		c.patchJump(endJump)
+		return nil, nil
+	}
+
+	elseJump := c.emitJump(OP_JUMP_IF_FALSE)
+	endJump := c.emitJump(OP_JUMP)
+	c.patchJump(elseJump)
+	c.emitOp(OP_POP)
+	c.compileExpr(expr.Right)
+	c.patchJump(endJump)
+	return nil, nil
+}
+
+func (c *Compiler) VisitMapLiteralExpr(expr *ast.MapLiteral) (any, error) {
+	for i, key := range expr.Keys {
+		c.compileExpr(key)
+		c.compileExpr(expr.Values[i])
+	}
+	c.token = expr.Brace
+	if len(expr.Keys) > maxElements {
+		c.error("too many entries in map literal.")
+	}
+	c.emitOpShort(OP_MAP, len(expr.Keys))
+	return nil, nil
+}
+
+func (c *Compiler) VisitSetExpr(expr *ast.Set) (any, error) {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Value)
+	c.token = expr.Name
+	c.emitOpShort(OP_SET_PROPERTY, c.identifierConstant(expr.Name))
+	return nil, nil
+}
+
+func (c *Compiler) VisitSliceExpr(expr *ast.Slice) (any, error) {
+	c.compileExpr(expr.Object)
+	for _, bound := range []ast.Expr{expr.Start, expr.End} {
+		if bound != nil {
+			c.compileExpr(bound)
+		} else {
+			c.emitOp(OP_NIL)
+		}
+	}
+	c.token = expr.Bracket
+	c.emitOp(OP_SLICE)
+	return nil, nil
+}
+
+func (c *Compiler) VisitSuperExpr(expr *ast.Super) (any, error) {
+	c.namedVariable(token.New(token.THIS, "this", nil, expr.Keyword.Line, expr.Keyword.Span), false)
+	c.namedVariable(expr.Keyword, false)
+	c.token = expr.Method
+	c.emitOpShort(OP_GET_SUPER, c.identifierConstant(expr.Method))
+	return nil, nil
+}
+
+func (c *Compiler) VisitThisExpr(expr *ast.This) (any, error) {
+	c.namedVariable(expr.Keyword, false)
+	return nil, nil
+}
+
+func (c *Compiler) VisitUnaryExpr(expr *ast.Unary) (any, error) {
+	c.compileExpr(expr.Right)
+
+	c.token = expr.Operator
+	switch expr.Operator.TokenType {
+	case token.BANG:
+		c.emitOp(OP_NOT)
+	case token.MINUS:
+		c.emitOp(OP_NEGATE)
+	default:
+		panic("unreachable code in VisitUnaryExpr")
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitVariableExpr(expr *ast.Variable) (any, error) {
+	c.namedVariable(expr.Name, false)
+	return nil, nil
+}
+
+func (c *Compiler) compileStmt(stmt ast.Stmt) {
+	stmt.Accept(c)
+}
+
+func (c *Compiler) compileExpr(expr ast.Expr) {
+	expr.Accept(c)
+}
+
+func (c *Compiler) chunk() *Chunk {
+	return c.current.function.Chunk
+}
+
+func (c *Compiler) beginFunction(name string, kind functionType) {
+	state := &functionState{
+		enclosing: c.current,
+		function:  &Function{Name: name, Chunk: &Chunk{}},
+		kind:      kind,
+	}
+	// Slot zero holds the receiver in methods and the callee otherwise.
+	slotZero := ""
+	if kind == typeMethod || kind == typeInitializer {
+		slotZero = "this"
+	}
+	state.locals = append(state.locals, local{name: slotZero, depth: 0})
+	c.current = state
+}
+
+func (c *Compiler) endFunction() (*Function, []upvalue) {
+	c.emitReturn()
+	state := c.current
+	c.current = state.enclosing
+	return state.function, state.upvalues
+}
+
+// function compiles the body of declaration and emits the closure creating
+// it in the enclosing function.
+func (c *Compiler) function(declaration *ast.Function, kind functionType) {
+	c.beginFunction(declaration.Name.Lexeme, kind)
+	c.beginScope()
+
+	for _, param := range declaration.Params {
+		c.token = param
+		c.current.function.Arity++
+		c.declareVariable(param.Lexeme)
+		c.markInitialized()
+	}
+	for _, stmt := range declaration.Body {
+		c.compileStmt(stmt)
+	}
+
+	c.token = declaration.Name
+	function, upvalues := c.endFunction()
+
+	c.token = declaration.Name
+	c.emitOpShort(OP_CLOSURE, c.makeConstant(function))
+	for _, uv := range upvalues {
+		isLocal := byte(0)
+		if uv.isLocal {
+			isLocal = 1
+		}
+		c.emitBytes(isLocal, byte(uv.index))
+	}
+}
+
+func (c *Compiler) beginScope() {
+	c.current.scopeDepth++
+}
+
+func (c *Compiler) endScope() {
+	state := c.current
+	state.scopeDepth--
+
+	for len(state.locals) > 0 && state.locals[len(state.locals)-1].depth > state.scopeDepth {
+		if state.locals[len(state.locals)-1].isCaptured {
+			c.emitOp(OP_CLOSE_UPVALUE)
+		} else {
+			c.emitOp(OP_POP)
+		}
+		state.locals = state.locals[:len(state.locals)-1]
+	}
+}
+
+func (c *Compiler) declareVariable(name string) {
+	if c.current.scopeDepth == 0 {
+		return
+	}
+	c.addLocal(name)
+}
+
+func (c *Compiler) addLocal(name string) {
+	if len(c.current.locals) == maxLocals {
+		c.error("too many local variables in function.")
+		return
+	}
+	// depth -1 marks the local as declared but not yet initialized.
+	c.current.locals = append(c.current.locals, local{name: name, depth: -1})
+}
+
+func (c *Compiler) markInitialized() {
+	if c.current.scopeDepth == 0 {
+		return
+	}
+	c.current.locals[len(c.current.locals)-1].depth = c.current.scopeDepth
+}
+
+// defineVariable finishes a declaration whose value is on top of the stack.
+func (c *Compiler) defineVariable(nameConstant int) {
+	if c.current.scopeDepth > 0 {
+		c.markInitialized()
+		return
+	}
+	c.emitOpShort(OP_DEFINE_GLOBAL, nameConstant)
+}
+
+func (c *Compiler) namedVariable(name token.Token, assign bool) {
+	c.token = name
+	if arg := resolveLocal(c.current, name.Lexeme); arg >= 0 {
+		if assign {
+			c.emitBytes(byte(OP_SET_LOCAL), byte(arg))
+		} else {
+			c.emitBytes(byte(OP_GET_LOCAL), byte(arg))
+		}
+	} else if arg := c.resolveUpvalue(c.current, name.Lexeme); arg >= 0 {
+		if assign {
+			c.emitBytes(byte(OP_SET_UPVALUE), byte(arg))
+		} else {
+			c.emitBytes(byte(OP_GET_UPVALUE), byte(arg))
+		}
+	} else if assign {
+		c.emitOpShort(OP_SET_GLOBAL, c.identifierConstant(name))
+	} else {
+		c.emitOpShort(OP_GET_GLOBAL, c.identifierConstant(name))
+	}
+}
+
+func resolveLocal(state *functionState, name string) int {
+	for i := len(state.locals) - 1; i >= 0; i-- {
+		if state.locals[i].name == name && state.locals[i].depth != -1 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Compiler) resolveUpvalue(state *functionState, name string) int {
+	if state.enclosing == nil {
+		return -1
+	}
+
+	if local := resolveLocal(state.enclosing, name); local >= 0 {
+		state.enclosing.locals[local].isCaptured = true
+		return c.addUpvalue(state, local, true)
+	}
+	if uv := c.resolveUpvalue(state.enclosing, name); uv >= 0 {
+		return c.addUpvalue(state, uv, false)
+	}
+	return -1
+}
+
+func (c *Compiler) addUpvalue(state *functionState, index int, isLocal bool) int {
+	for i, uv := range state.upvalues {
+		if uv.index == index && uv.isLocal == isLocal {
+			return i
+		}
+	}
+
+	if len(state.upvalues) == maxUpvalues {
+		c.error("too many closure variables in function.")
+		return 0
+	}
+	state.upvalues = append(state.upvalues, upvalue{index: index, isLocal: isLocal})
+	state.function.UpvalueCount = len(state.upvalues)
+	return len(state.upvalues) - 1
+}
+
+func (c *Compiler) identifierConstant(name token.Token) int {
+	return c.makeConstant(name.Lexeme)
+}
+
+func (c *Compiler) makeConstant(value any) int {
+	index := c.chunk().AddConstant(value)
+	if index >= maxConstants {
+		c.error("too many constants in one chunk.")
+		return 0
+	}
+	return index
+}
+
+func (c *Compiler) emitOp(op OpCode) {
+	c.chunk().Write(byte(op), c.token)
+}
+
+func (c *Compiler) emitBytes(bytes ...byte) {
+	for _, b := range bytes {
+		c.chunk().Write(b, c.token)
+	}
+}
+
+func (c *Compiler) emitOpShort(op OpCode, operand int) {
+	c.emitBytes(byte(op), byte(operand>>8), byte(operand))
+}
+
+func (c *Compiler) emitReturn() {
+	if c.current.kind == typeInitializer {
+		c.emitBytes(byte(OP_GET_LOCAL), 0)
+	} else {
+		c.emitOp(OP_NIL)
+	}
+	c.emitOp(OP_RETURN)
+}
+
+// emitJump emits op with a placeholder offset and returns the offset's
+// position for patchJump.
+func (c *Compiler) emitJump(op OpCode) int {
+	c.emitBytes(byte(op), 0xff, 0xff)
+	return len(c.chunk().Code) - 2
+}
+
+func (c *Compiler) patchJump(offset int) {
+	jump := len(c.chunk().Code) - offset - 2
+	if jump > maxJump {
+		c.error("too much code to jump over.")
+	}
+	c.chunk().Code[offset] = byte(jump >> 8)
+	c.chunk().Code[offset+1] = byte(jump)
+}
+
+func (c *Compiler) emitLoop(loopStart int) {
+	c.emitOp(OP_LOOP)
+	offset := len(c.chunk().Code) - loopStart + 2
+	if offset > maxJump {
+		c.error("loop body too large.")
+	}
+	c.emitBytes(byte(offset>>8), byte(offset))
+}
+
+func (c *Compiler) error(message string) {
+	c.reporter.Report(errors.AtToken(c.token, errors.CodeCompile, message))
+	c.hadError = true
+}
@@ -0,0 +1,93 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+)
+
+// Disassemble writes a listing of function's chunk to w, followed by the
+// listings of every function nested in it.
+func Disassemble(w io.Writer, function *Function) {
+	chunk := function.Chunk
+	fmt.Fprintf(w, "== %s ==\n", function)
+	for offset := 0; offset < len(chunk.Code); {
+		offset = DisassembleInstruction(w, chunk, offset)
+	}
+
+	for _, constant := range chunk.Constants {
+		if nested, ok := constant.(*Function); ok {
+			fmt.Fprintln(w)
+			Disassemble(w, nested)
+		}
+	}
+}
+
+// DisassembleInstruction writes the instruction at offset and returns the
+// offset of the next one.
+func DisassembleInstruction(w io.Writer, chunk *Chunk, offset int) int {
+	fmt.Fprintf(w, "%04d ", offset)
+	if offset > 0 && chunk.Line(offset) == chunk.Line(offset-1) {
+		fmt.Fprint(w, "   | ")
+	} else {
+		fmt.Fprintf(w, "%4d ", chunk.Line(offset))
+	}
+
+	op := OpCode(chunk.Code[offset])
+	switch op {
+	case OP_CONSTANT, OP_GET_GLOBAL, OP_DEFINE_GLOBAL, OP_SET_GLOBAL,
+		OP_GET_PROPERTY, OP_SET_PROPERTY, OP_GET_SUPER, OP_CLASS, OP_METHOD:
+		return constantInstruction(w, op, chunk, offset)
+	case OP_GET_LOCAL, OP_SET_LOCAL, OP_GET_UPVALUE, OP_SET_UPVALUE, OP_CALL:
+		return byteInstruction(w, op, chunk, offset)
+	case OP_LIST, OP_MAP:
+		return shortInstruction(w, op, chunk, offset)
+	case OP_JUMP, OP_JUMP_IF_FALSE:
+		return jumpInstruction(w, op, 1, chunk, offset)
+	case OP_LOOP:
+		return jumpInstruction(w, op, -1, chunk, offset)
+	case OP_CLOSURE:
+		return closureInstruction(w, chunk, offset)
+	default:
+		fmt.Fprintln(w, op)
+		return offset + 1
+	}
+}
+
+func constantInstruction(w io.Writer, op OpCode, chunk *Chunk, offset int) int {
+	constant := chunk.ReadShort(offset + 1)
+	fmt.Fprintf(w, "%-16s %4d '%v'\n", op, constant, chunk.Constants[constant])
+	return offset + 3
+}
+
+func byteInstruction(w io.Writer, op OpCode, chunk *Chunk, offset int) int {
+	fmt.Fprintf(w, "%-16s %4d\n", op, chunk.Code[offset+1])
+	return offset + 2
+}
+
+func shortInstruction(w io.Writer, op OpCode, chunk *Chunk, offset int) int {
+	fmt.Fprintf(w, "%-16s %4d\n", op, chunk.ReadShort(offset+1))
+	return offset + 3
+}
+
+func jumpInstruction(w io.Writer, op OpCode, sign int, chunk *Chunk, offset int) int {
+	jump := chunk.ReadShort(offset + 1)
+	fmt.Fprintf(w, "%-16s %4d -> %d\n", op, offset, offset+3+sign*jump)
+	return offset + 3
+}
+
+func closureInstruction(w io.Writer, chunk *Chunk, offset int) int {
+	constant := chunk.ReadShort(offset + 1)
+	function := chunk.Constants[constant].(*Function)
+	fmt.Fprintf(w, "%-16s %4d %v\n", OP_CLOSURE, constant, function)
+
+	offset += 3
+	for range function.UpvalueCount {
+		kind := "upvalue"
+		if chunk.Code[offset] == 1 {
+			kind = "local"
+		}
+		fmt.Fprintf(w, "%04d    |                     %s %d\n", offset, kind, chunk.Code[offset+1])
+		offset += 2
+	}
+	return offset
+}
@@ -0,0 +1,292 @@
+package glox
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// conformance is the corpus both backends must agree on. want is the
+// transcript of running src, see transcript.
+var conformance = []struct {
+	name string
+	src  string
+	want string
+	// depth, if set, is the MaxCallDepth to run with.
+	depth int
+}{
+	{
+		name: "arithmetic",
+		src:  `print 1 + 2 * 3; print (1 + 2) * 3; print 10 / 4; print -(3 - 5); print "a" + "b"; print 1 == 1.0;`,
+		want: "7\n9\n2.5\n2\nab\ntrue\n",
+	},
+	{
+		name: "logic and control flow",
+		src: `
+print nil or "default";
+print false and boom();
+var sum = 0;
+for (var i = 0; i < 5; i = i + 1) {
+  if (i == 2) sum = sum + 10; else sum = sum + i;
+}
+print sum;
+var n = 3;
+while (n > 0) n = n - 1;
+print n;`,
+		want: "default\nfalse\n18\n0\n",
+	},
+	{
+		name: "closures",
+		src: `
+fun makeCounter() {
+  var count = 0;
+  fun increment() {
+    count = count + 1;
+    return count;
+  }
+  return increment;
+}
+var a = makeCounter();
+var b = makeCounter();
+print a();
+print a();
+print b();`,
+		want: "1\n2\n1\n",
+	},
+	{
+		name: "shared upvalues",
+		src: `
+var get;
+var set;
+{
+  var x = "before";
+  fun g() { return x; }
+  fun s(value) { x = value; }
+  get = g;
+  set = s;
+}
+print get();
+set("after");
+print get();`,
+		want: "before\nafter\n",
+	},
+	{
+		name: "upvalues close over each iteration's scope",
+		src: `
+var fns = [];
+for (var i = 0; i < 3; i = i + 1) {
+  var j = i;
+  fun f() { return j; }
+  fns.push(f);
+}
+print fns[0]();
+print fns[2]();`,
+		want: "0\n2\n",
+	},
+	{
+		name: "static scope",
+		src: `
+var a = "global";
+{
+  fun show() { print a; }
+  show();
+  var a = "block";
+  show();
+}`,
+		want: "global\nglobal\n",
+	},
+	{
+		name: "classes",
+		src: `
+class Point {
+  init(x, y) {
+    this.x = x;
+    this.y = y;
+  }
+  sum() { return this.x + this.y; }
+}
+var p = Point(1, 2);
+print p.sum();
+var sum = p.sum;
+p.x = 10;
+print sum();
+print p;
+print Point;
+print p.init(3, 4) == p;`,
+		want: "3\n12\nPoint instance\nPoint\ntrue\n",
+	},
+	{
+		name: "inheritance and super",
+		src: `
+class A {
+  init(name) { this.name = name; }
+  greet() { return "A " + this.name; }
+  shout() { return this.greet() + "!"; }
+}
+class B < A {
+  init(name) { super.init(name + "?"); }
+  greet() { return "B then " + super.greet(); }
+}
+var b = B("bee");
+print b.greet();
+print b.shout();`,
+		want: "B then A bee?\nB then A bee?!\n",
+	},
+	{
+		name: "lists and maps",
+		src: `
+var l = [1, "two", nil];
+l.push(4);
+l[0] = 0;
+print l;
+print l[1:3];
+print l.len();
+var m = {"a": 1, 2: "b"};
+m["c"] = [m["a"]];
+print m;
+print m.keys();
+print len("héllo");
+print substr("héllo", 1, 3);`,
+		want: "[0, \"two\", nil, 4]\n[\"two\", nil]\n4\n{\"a\": 1, 2: \"b\", \"c\": [1]}\n[\"a\", 2, \"c\"]\n5\néll\n",
+	},
+	{
+		name: "constant expressions",
+		src: `
+print 0 / 0 == 0 / 0;
+print 0 == -0;
+print nil == false;
+print "a" + "b" == "ab";
+print !nil;
+print true and "x";
+print nil or false;
+print -(-2);`,
+		want: "false\ntrue\nfalse\ntrue\ntrue\nx\nfalse\n2\n",
+	},
+	{
+		name: "constant conditions",
+		src: `
+var effects = 0;
+if (false) print "then"; else effects = effects + 1;
+if (true) effects = effects + 10;
+while (false) print "never";
+for (; false;) print "never";
+print effects;`,
+		want: "11\n",
+	},
+	{
+		name: "constant operand errors",
+		src:  "print \"a\" - 1;",
+		want: "[line 1] Error at '-': Left operand must be a number.\n",
+	},
+	{
+		name: "runtime error at top level",
+		src:  "print \"before\";\nprint -\"x\";\nprint \"after\";",
+		want: "before\n[line 2] Error at '-': Operand must be a number.\n",
+	},
+	{
+		name: "runtime error trace",
+		src: `
+fun inner(x) {
+  return x + nil;
+}
+fun outer() {
+  return inner(1);
+}
+outer();`,
+		want: "[line 3] Error at '+': Both operands must be either string or number.\n" +
+			"  in outer called at line 8\n" +
+			"  in inner called at line 6\n",
+	},
+	{
+		name: "native error trace",
+		src: `
+fun f() { return substr("abc", 2, 5); }
+f();`,
+		want: "[line 2] Error at ')': Substring out of range.\n" +
+			"  in f called at line 3\n" +
+			"  in substr called at line 2\n",
+	},
+	{
+		name: "method error trace",
+		src: `
+class C {
+  m() { return this.missing; }
+}
+C().m();`,
+		want: "[line 3] Error at 'missing': Undefined property 'missing'.\n" +
+			"  in m called at line 5\n",
+	},
+	{
+		name:  "stack overflow",
+		src:   "fun f(n) {\n  return f(n + 1);\n}\nf(0);",
+		depth: 3,
+		want: "[line 2] Error at ')': Stack overflow.\n" +
+			"  in f called at line 4\n" +
+			"  in f called at line 2\n" +
+			"  in f called at line 2\n",
+	},
+	{
+		name: "static errors",
+		src:  "return 1;\nvar x = ;",
+		want: "[line 2] Error at ';': expression expected\n",
+	},
+	{
+		name: "resolve errors",
+		src:  "return 1;\n{ var a = a; }",
+		want: "[line 1] Error at 'return': can't return from top-level code.\n" +
+			"[line 2] Error at 'a': can't read local variable in its own initializer.\n",
+	},
+}
+
+// transcript runs src and returns what it printed followed by its
+// diagnostics, each with the calls that were active, outermost first.
+func transcript(opts Options, src string) string {
+	var out strings.Builder
+	opts.Stdout = &out
+	rt := NewRuntime(opts)
+	err := rt.Eval(context.Background(), src)
+
+	var evalErr *Error
+	if err != nil && !stderrors.As(err, &evalErr) {
+		fmt.Fprintln(&out, err)
+		return out.String()
+	}
+	if evalErr == nil {
+		return out.String()
+	}
+	for _, d := range evalErr.Diagnostics {
+		fmt.Fprintln(&out, d)
+		for _, frame := range d.Trace {
+			fmt.Fprintf(&out, "  in %s called at line %d\n", frame.Function, frame.CallSite.Line)
+		}
+	}
+	return out.String()
+}
+
+func TestConformance(t *testing.T) {
+	for _, test := range conformance {
+		for name, backend := range backends {
+			got := transcript(Options{Backend: backend, MaxCallDepth: test.depth}, test.src)
+			if got != test.want {
+				t.Errorf("%s/%s:\ngot:\n%s\nwant:\n%s", test.name, name, got, test.want)
+			}
+		}
+	}
+}
+
+// TestOptimizerPreservesOutput checks that constant folding and dead branch
+// elimination never change what a program does.
+func TestOptimizerPreservesOutput(t *testing.T) {
+	for _, test := range conformance {
+		for name, backend := range backends {
+			opts := Options{Backend: backend, MaxCallDepth: test.depth}
+			want := transcript(opts, test.src)
+			opts.Optimize = true
+			if got := transcript(opts, test.src); got != want {
+				t.Errorf("%s/%s: optimized:\n%s\nunoptimized:\n%s", test.name, name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/anwprath/glox/token"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Code identifies the phase that produced a diagnostic.
+type Code string
+
+const (
+	CodeScan    Code = "scan"
+	CodeParse   Code = "parse"
+	CodeResolve Code = "resolve"
+	CodeCompile Code = "compile"
+	CodeRuntime Code = "runtime"
+)
+
+type Diagnostic struct {
+	Severity Severity
+	Code     Code
+	Message  string
+	Span     token.Span
+	// Where describes the offending token, e.g. " at 'x'" or " at end". It
+	// is empty when the diagnostic is not tied to a token.
+	Where string
+	// Trace is the Lox call stack of a runtime error, outermost call first.
+	Trace []Frame
+}
+
+// String formats d on one line in the classic Lox layout.
+func (d Diagnostic) String() string {
+	label := "Error"
+	if d.Severity == SeverityWarning {
+		label = "Warning"
+	}
+	return fmt.Sprintf("[line %d] %s%s: %s", d.Span.Line, label, d.Where, d.Message)
+}
+
+// Reporter receives diagnostics from the scanner, parser, resolver and
+// interpreter. Each run should use its own Reporter, which is what lets
+// several of them run side by side.
+type Reporter interface {
+	Report(d Diagnostic)
+}
+
+// At builds an error diagnostic covering span, not tied to a token.
+func At(span token.Span, code Code, message string) Diagnostic {
+	return Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Message:  message,
+		Span:     span,
+	}
+}
+
+// AtToken builds an error diagnostic pointing at t.
+func AtToken(t token.Token, code Code, message string) Diagnostic {
+	d := At(t.Span, code, message)
+	if t.TokenType == token.EOF {
+		d.Where = " at end"
+	} else {
+		d.Where = " at '" + t.Lexeme + "'"
+	}
+	return d
+}
+
+// Collector is a Reporter that keeps every diagnostic in memory.
+type Collector struct {
+	Diagnostics []Diagnostic
+}
+
+func (c *Collector) Report(d Diagnostic) {
+	c.Diagnostics = append(c.Diagnostics, d)
+}
+
+func (c *Collector) HasErrors() bool {
+	for _, d := range c.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
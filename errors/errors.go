@@ -1,36 +1,32 @@
 package errors
 
 import (
-	"fmt"
 	"strconv"
-
-	"github.com/anwprath/glox/token"
 )
 
-var HadError bool = false
-var HadRuntimeError bool = false
+type ScanErr struct{}
 
-func Error(line int, message string) {
-	report(line, "", message)
+func (s ScanErr) Error() string {
+	return ""
 }
 
-func report(line int, where, message string) {
-	fmt.Println("[line " + strconv.Itoa(line) + "] Error" + where + ": " + message)
-	HadError = true
+type ParseErr struct {
+	Line    int
+	Message string
 }
 
-func ReportParseError(t token.Token, message string) error {
-	if t.TokenType == token.EOF {
-		report(t.Line, " at end", message)
-	} else {
-		report(t.Line, " at '"+t.Lexeme+"'", message)
-	}
+func (p ParseErr) Error() string {
+	return "[line " + strconv.Itoa(p.Line) + "] " + p.Message
+}
 
-	return ParseErr{}
+type ResolveErr struct{}
+
+func (r ResolveErr) Error() string {
+	return ""
 }
 
-type ParseErr struct{}
+type CompileErr struct{}
 
-func (p ParseErr) Error() string {
+func (c CompileErr) Error() string {
 	return ""
 }
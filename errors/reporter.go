@@ -0,0 +1,153 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anwprath/glox/source"
+	"github.com/anwprath/glox/token"
+)
+
+// TerminalReporter prints diagnostics in the classic Lox layout followed by
+// the offending source line, underlined:
+//
+//	[line 1] Error at 'x': message
+//	  --> script.lox:1:9
+//	   1 | var x = ;
+//	     |         ^
+//
+// and remembers whether any static or runtime error was seen. Spans are
+// resolved against files.
+type TerminalReporter struct {
+	out             io.Writer
+	files           *source.FileSet
+	hadError        bool
+	hadRuntimeError bool
+}
+
+func NewTerminalReporter(out io.Writer, files *source.FileSet) *TerminalReporter {
+	return &TerminalReporter{out: out, files: files}
+}
+
+func (r *TerminalReporter) Report(d Diagnostic) {
+	line := strconv.Itoa(d.Span.Line)
+	if d.Code == CodeRuntime {
+		r.printTraceback(d)
+		fmt.Fprintln(r.out, "runtime error"+d.Where+": "+d.Message+"\n[line "+line+"]")
+		r.printExcerpt(d.Span)
+		r.hadRuntimeError = true
+		return
+	}
+
+	label := "Error"
+	if d.Severity == SeverityWarning {
+		label = "Warning"
+	}
+	fmt.Fprintln(r.out, "[line "+line+"] "+label+d.Where+": "+d.Message)
+	r.printExcerpt(d.Span)
+	if d.Severity == SeverityError {
+		r.hadError = true
+	}
+}
+
+// printTraceback prints the call chain of a runtime error the way Python
+// does, one line per active function with the line it was executing:
+//
+//	Traceback (most recent call last):
+//	  File "script.lox", line 9, in <script>
+//	  File "script.lox", line 4, in inner
+//
+// Runs of identical lines, as left by deep recursion, are collapsed after
+// the first few.
+func (r *TerminalReporter) printTraceback(d Diagnostic) {
+	fmt.Fprintln(r.out, "Traceback (most recent call last):")
+	var entries []string
+	function := "<script>"
+	for _, frame := range d.Trace {
+		entries = append(entries, r.traceEntry(frame.CallSite, function))
+		function = frame.Function
+	}
+	entries = append(entries, r.traceEntry(d.Span, function))
+
+	const maxRepeats = 3
+	repeats := 0
+	for i, entry := range entries {
+		if i > 0 && entry == entries[i-1] {
+			repeats++
+		} else {
+			r.printRepeats(repeats - maxRepeats)
+			repeats = 1
+		}
+		if repeats <= maxRepeats {
+			fmt.Fprint(r.out, entry)
+		}
+	}
+	r.printRepeats(repeats - maxRepeats)
+}
+
+func (r *TerminalReporter) traceEntry(span token.Span, function string) string {
+	name := "<unknown>"
+	if file := r.files.File(span.File); file != nil {
+		name = file.Name
+	}
+	return fmt.Sprintf("  File %q, line %d, in %s\n", name, span.Line, function)
+}
+
+func (r *TerminalReporter) printRepeats(n int) {
+	if n > 0 {
+		fmt.Fprintf(r.out, "  [Previous line repeated %d more times]\n", n)
+	}
+}
+
+func (r *TerminalReporter) printExcerpt(span token.Span) {
+	if span.IsZero() {
+		return
+	}
+	file := r.files.File(span.File)
+	if file == nil || span.Start > len(file.Content) {
+		return
+	}
+	fmt.Fprintln(r.out, "  --> "+r.files.Position(span.File, span.Line, span.Column).String())
+
+	text := file.Line(span.Line)
+
+	// Underline up to the end of the first line only.
+	lineStart := strings.LastIndexByte(file.Content[:span.Start], '\n') + 1
+	lineEnd := lineStart + len(text)
+	width := utf8.RuneCountInString(file.Content[span.Start:min(max(span.End, span.Start), lineEnd)])
+	width = max(width, 1)
+
+	// Keep tabs in the padding so the caret lines up with the text above.
+	padding := strings.Builder{}
+	for i, c := range []rune(text) {
+		if i >= span.Column-1 {
+			break
+		}
+		if c == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	gutter := fmt.Sprintf("%4d | ", span.Line)
+	fmt.Fprintln(r.out, gutter+text)
+	fmt.Fprintln(r.out, strings.Repeat(" ", len(gutter)-2)+"| "+padding.String()+strings.Repeat("^", width))
+}
+
+func (r *TerminalReporter) HadError() bool {
+	return r.hadError
+}
+
+func (r *TerminalReporter) HadRuntimeError() bool {
+	return r.hadRuntimeError
+}
+
+// Reset forgets previous errors, e.g. between REPL lines.
+func (r *TerminalReporter) Reset() {
+	r.hadError = false
+	r.hadRuntimeError = false
+}
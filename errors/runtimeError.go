@@ -1,22 +1,51 @@
 package errors
 
 import (
-	"fmt"
-	"strconv"
+	stderrors "errors"
 
 	"github.com/anwprath/glox/token"
 )
 
+// Frame is one active Lox call: the name of the callee and the span of the
+// call expression that invoked it.
+type Frame struct {
+	Function string
+	CallSite token.Span
+}
+
+var (
+	// ErrCanceled is the cause of runtime errors raised because the
+	// execution's context was canceled or its deadline passed.
+	ErrCanceled = stderrors.New("execution canceled")
+	// ErrBudgetExceeded is the cause of runtime errors raised because a
+	// script used up its step budget.
+	ErrBudgetExceeded = stderrors.New("step budget exceeded")
+	// ErrMemoryLimit is the cause of runtime errors raised because a script
+	// allocated more than its memory limit.
+	ErrMemoryLimit = stderrors.New("memory limit exceeded")
+)
+
 type RuntimeError struct {
 	Token   token.Token
 	Message string
+	// Trace holds the calls active when the error was raised, outermost
+	// first. It is empty for errors raised in top-level code.
+	Trace []Frame
+	// Cause, if set, is the host-side reason for aborting the script, such
+	// as ErrCanceled.
+	Cause error
 }
 
 func (e RuntimeError) Error() string {
-	return fmt.Sprintf("runtime error at token %v: %s", e.Token, e.Message)
+	return "runtime error" + e.Diagnostic().Where + ": " + e.Message
+}
+
+func (e RuntimeError) Unwrap() error {
+	return e.Cause
 }
 
-func ReportRuntimeError(err RuntimeError) {
-	fmt.Println(err.Error() + "\n[line " + strconv.Itoa(err.Token.Line) + "]")
-	HadRuntimeError = true
+func (e RuntimeError) Diagnostic() Diagnostic {
+	d := AtToken(e.Token, CodeRuntime, e.Message)
+	d.Trace = e.Trace
+	return d
 }
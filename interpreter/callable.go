@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"github.com/anwprath/glox/ast"
+	"github.com/anwprath/glox/native"
+)
+
+// LoxCallable is any value that can appear as the callee of a call
+// expression.
+type LoxCallable interface {
+	Arity() int
+	Call(interp *Interpreter, args []any) (any, error)
+}
+
+var _ LoxCallable = &LoxFunction{}
+var _ LoxCallable = &NativeFunction{}
+
+type LoxFunction struct {
+	declaration   *ast.Function
+	closure       *Environment
+	isInitializer bool
+}
+
+func NewLoxFunction(declaration *ast.Function, closure *Environment, isInitializer bool) *LoxFunction {
+	return &LoxFunction{
+		declaration:   declaration,
+		closure:       closure,
+		isInitializer: isInitializer,
+	}
+}
+
+// Bind returns a copy of f whose closure has "this" bound to instance.
+func (f *LoxFunction) Bind(instance *LoxInstance) *LoxFunction {
+	environment := NewEnvironment(f.closure)
+	environment.Define("this", instance)
+	return NewLoxFunction(f.declaration, environment, f.isInitializer)
+}
+
+func (f *LoxFunction) Arity() int {
+	return len(f.declaration.Params)
+}
+
+func (f *LoxFunction) Call(interp *Interpreter, args []any) (any, error) {
+	environment := NewEnvironment(f.closure)
+	for idx, param := range f.declaration.Params {
+		environment.Define(param.Lexeme, args[idx])
+	}
+
+	err := interp.executeBlock(f.declaration.Body, environment)
+	if ret, ok := err.(returnValue); ok {
+		if f.isInitializer {
+			return f.closure.GetAt(0, "this"), nil
+		}
+		return ret.Value, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// init() always yields the instance, even when called directly.
+	if f.isInitializer {
+		return f.closure.GetAt(0, "this"), nil
+	}
+	return nil, nil
+}
+
+func (f *LoxFunction) String() string {
+	return "<fn " + f.declaration.Name.Lexeme + ">"
+}
+
+// returnValue unwinds the Go call stack from a `return` statement back to
+// the enclosing LoxFunction.Call. It travels through the regular error
+// results of the visitor methods so no panic/recover is needed.
+type returnValue struct {
+	Value any
+}
+
+func (r returnValue) Error() string {
+	return "return outside of function call"
+}
+
+// NativeFunction adapts a host Go function to LoxCallable.
+type NativeFunction struct {
+	*native.Function
+}
+
+// Call invokes f without charging its allocations. VisitCallExpr invokes
+// natives itself so that they are charged at the call site.
+func (f *NativeFunction) Call(interp *Interpreter, args []any) (any, error) {
+	return f.Invoke(nil, args)
+}
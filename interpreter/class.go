@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/token"
+)
+
+var _ LoxCallable = &LoxClass{}
+
+type LoxClass struct {
+	Name       string
+	superclass *LoxClass
+	methods    map[string]*LoxFunction
+}
+
+func NewLoxClass(name string, superclass *LoxClass, methods map[string]*LoxFunction) *LoxClass {
+	return &LoxClass{
+		Name:       name,
+		superclass: superclass,
+		methods:    methods,
+	}
+}
+
+// FindMethod looks name up on the class and then along its superclass chain.
+func (c *LoxClass) FindMethod(name string) (*LoxFunction, bool) {
+	if method, ok := c.methods[name]; ok {
+		return method, true
+	}
+	if c.superclass != nil {
+		return c.superclass.FindMethod(name)
+	}
+	return nil, false
+}
+
+func (c *LoxClass) Arity() int {
+	if initializer, ok := c.FindMethod("init"); ok {
+		return initializer.Arity()
+	}
+	return 0
+}
+
+func (c *LoxClass) Call(interp *Interpreter, args []any) (any, error) {
+	instance := NewLoxInstance(c)
+	if initializer, ok := c.FindMethod("init"); ok {
+		if _, err := initializer.Bind(instance).Call(interp, args); err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+func (c *LoxClass) String() string {
+	return c.Name
+}
+
+type LoxInstance struct {
+	class  *LoxClass
+	fields map[string]any
+}
+
+func NewLoxInstance(class *LoxClass) *LoxInstance {
+	return &LoxInstance{
+		class:  class,
+		fields: make(map[string]any),
+	}
+}
+
+// Get returns the field called name, or else the method bound to the
+// instance. Fields shadow methods.
+func (i *LoxInstance) Get(name token.Token) (any, error) {
+	if value, ok := i.fields[name.Lexeme]; ok {
+		return value, nil
+	}
+
+	if method, ok := i.class.FindMethod(name.Lexeme); ok {
+		return method.Bind(i), nil
+	}
+
+	return nil, errors.RuntimeError{
+		Token:   name,
+		Message: "Undefined property '" + name.Lexeme + "'.",
+	}
+}
+
+func (i *LoxInstance) Set(name token.Token, value any) {
+	i.fields[name.Lexeme] = value
+}
+
+func (i *LoxInstance) String() string {
+	return i.class.Name + " instance"
+}
@@ -0,0 +1,71 @@
+package interpreter
+
+import (
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/token"
+)
+
+type Environment struct {
+	enclosing *Environment
+	values    map[string]any
+}
+
+// NewEnvironment creates a scope nested inside enclosing. Pass nil for the
+// global scope.
+func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{
+		enclosing: enclosing,
+		values:    make(map[string]any),
+	}
+}
+
+func (e *Environment) Define(name string, value any) {
+	e.values[name] = value
+}
+
+func (e *Environment) Get(name token.Token) (any, error) {
+	if value, ok := e.values[name.Lexeme]; ok {
+		return value, nil
+	}
+	if e.enclosing != nil {
+		return e.enclosing.Get(name)
+	}
+
+	return nil, errors.RuntimeError{
+		Token:   name,
+		Message: "Undefined variable '" + name.Lexeme + "'.",
+	}
+}
+
+func (e *Environment) Assign(name token.Token, value any) error {
+	if _, ok := e.values[name.Lexeme]; ok {
+		e.values[name.Lexeme] = value
+		return nil
+	}
+	if e.enclosing != nil {
+		return e.enclosing.Assign(name, value)
+	}
+
+	return errors.RuntimeError{
+		Token:   name,
+		Message: "Undefined variable '" + name.Lexeme + "'.",
+	}
+}
+
+// GetAt reads name from the scope exactly distance hops up the chain, as
+// computed by the resolver.
+func (e *Environment) GetAt(distance int, name string) any {
+	return e.ancestor(distance).values[name]
+}
+
+func (e *Environment) AssignAt(distance int, name token.Token, value any) {
+	e.ancestor(distance).values[name.Lexeme] = value
+}
+
+func (e *Environment) ancestor(distance int) *Environment {
+	environment := e
+	for range distance {
+		environment = environment.enclosing
+	}
+	return environment
+}
@@ -1,30 +1,304 @@
 package interpreter
 
 import (
+	"context"
 	"fmt"
-	"reflect"
-	"strconv"
-	"strings"
+	"io"
+	"os"
+	"slices"
 
 	"github.com/anwprath/glox/ast"
 	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/native"
+	"github.com/anwprath/glox/object"
 	"github.com/anwprath/glox/token"
 )
 
 var _ ast.ExprVisitor = &Interpreter{}
+var _ ast.StmtVisitor = &Interpreter{}
 
-type Interpreter struct{}
+type Interpreter struct {
+	globals     *Environment
+	environment *Environment
+	// locals maps each resolved variable expression to the number of scopes
+	// between its use and its declaration. Globals are absent.
+	locals map[ast.Expr]int
+	// topLevel holds the keys of locals in top-level code, which are
+	// forgotten once Interpret has run that code.
+	topLevel []ast.Expr
+	// frames is the Lox call stack, outermost call first.
+	frames   []errors.Frame
+	reporter errors.Reporter
+	// out receives the output of print statements.
+	out io.Writer
+	// ctx and budget bound the running script; steps counts the calls and
+	// loop iterations it has made so far.
+	ctx    context.Context
+	budget int
+	steps  int
+	// maxDepth bounds len(frames). allocated approximates the bytes the
+	// running script has allocated, see allocate.
+	maxDepth    int
+	memoryLimit int
+	allocated   int
+}
+
+func New(reporter errors.Reporter) *Interpreter {
+	globals := NewEnvironment(nil)
+	return &Interpreter{
+		reporter:    reporter,
+		out:         os.Stdout,
+		globals:     globals,
+		environment: globals,
+		locals:      make(map[ast.Expr]int),
+		ctx:         context.Background(),
+		maxDepth:    object.DefaultMaxCallDepth,
+	}
+}
+
+// SetOutput redirects print statements to w.
+func (i *Interpreter) SetOutput(w io.Writer) {
+	i.out = w
+}
+
+// SetStepBudget limits each Interpret call to n steps, where a step is a
+// function call or a loop iteration. Zero means no limit.
+func (i *Interpreter) SetStepBudget(n int) {
+	i.budget = n
+}
+
+// SetMaxCallDepth limits how deeply Lox calls may nest. Zero restores
+// object.DefaultMaxCallDepth. Very large limits may exhaust the Go stack.
+func (i *Interpreter) SetMaxCallDepth(n int) {
+	if n <= 0 {
+		n = object.DefaultMaxCallDepth
+	}
+	i.maxDepth = n
+}
+
+// SetMemoryLimit limits the approximate number of bytes of strings, objects
+// and functions each Interpret call may allocate. Zero means no limit.
+func (i *Interpreter) SetMemoryLimit(bytes int) {
+	i.memoryLimit = bytes
+}
+
+// DefineGlobal binds name to value in the global scope, replacing any
+// previous binding.
+func (i *Interpreter) DefineGlobal(name string, value any) {
+	i.globals.Define(name, value)
+}
+
+// GetGlobal returns the value bound to name in the global scope.
+func (i *Interpreter) GetGlobal(name string) (any, bool) {
+	value, ok := i.globals.values[name]
+	return value, ok
+}
+
+// DefineNative makes fn callable from Lox as the global function name. An
+// error returned by fn is raised as a runtime error at the call site.
+func (i *Interpreter) DefineNative(name string, arity int, fn func(args []any) (any, error)) {
+	i.DefineFunction(native.New(name, arity, fn))
+}
+
+// DefineFunction makes function callable from Lox under its name. What it
+// allocates through its native.Allocator counts against the memory limit.
+func (i *Interpreter) DefineFunction(function *native.Function) {
+	i.globals.Define(function.Name(), &NativeFunction{function})
+}
+
+// DefineFunc is DefineNative for an ordinary Go function, whose parameters
+// and results are converted with reflection. See native.Wrap.
+func (i *Interpreter) DefineFunc(name string, fn any) error {
+	function, err := native.Wrap(name, fn)
+	if err != nil {
+		return err
+	}
+	i.DefineFunction(function)
+	return nil
+}
+
+// Resolve records that expr refers to a local declared depth scopes up. It is
+// called by the resolver before Interpret runs.
+func (i *Interpreter) Resolve(expr ast.Expr, depth int) {
+	i.locals[expr] = depth
+}
+
+// ResolveTopLevel is Resolve for expressions outside any function. They are
+// forgotten after the next Interpret call, so that a long-lived interpreter
+// only keeps the bindings of functions that may still be called.
+func (i *Interpreter) ResolveTopLevel(expr ast.Expr, depth int) {
+	i.locals[expr] = depth
+	i.topLevel = append(i.topLevel, expr)
+}
+
+// Interpret runs stmts. Runtime errors are reported and returned.
+func (i *Interpreter) Interpret(stmts []ast.Stmt) error {
+	return i.InterpretContext(context.Background(), stmts)
+}
+
+// InterpretContext is Interpret, but aborts with a runtime error wrapping
+// errors.ErrCanceled once ctx is done.
+func (i *Interpreter) InterpretContext(ctx context.Context, stmts []ast.Stmt) error {
+	i.ctx, i.steps, i.allocated = ctx, 0, 0
+	defer i.forgetTopLevel()
+	for _, stmt := range stmts {
+		err := i.execute(stmt)
+		if err != nil {
+			if runtimeErr, ok := err.(errors.RuntimeError); ok {
+				i.reporter.Report(i.withTrace(runtimeErr).Diagnostic())
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Interpreter) forgetTopLevel() {
+	for _, expr := range i.topLevel {
+		delete(i.locals, expr)
+	}
+	i.topLevel = nil
+}
+
+func (i *Interpreter) VisitBlockStmt(stmt *ast.Block) (any, error) {
+	return nil, i.executeBlock(stmt.Statements, NewEnvironment(i.environment))
+}
+
+func (i *Interpreter) VisitClassStmt(stmt *ast.Class) (any, error) {
+	var superclass *LoxClass
+	if stmt.Superclass != nil {
+		value, err := i.evaluate(stmt.Superclass)
+		if err != nil {
+			return nil, err
+		}
+		class, ok := value.(*LoxClass)
+		if !ok {
+			return nil, errors.RuntimeError{
+				Token:   stmt.Superclass.Name,
+				Message: "Superclass must be a class.",
+			}
+		}
+		superclass = class
+	}
+
+	if err := i.allocate(stmt.Name, object.ClassSize+len(stmt.Methods)*object.FunctionSize); err != nil {
+		return nil, err
+	}
+	i.environment.Define(stmt.Name.Lexeme, nil)
+
+	if superclass != nil {
+		i.environment = NewEnvironment(i.environment)
+		i.environment.Define("super", superclass)
+	}
+
+	methods := make(map[string]*LoxFunction, len(stmt.Methods))
+	for _, method := range stmt.Methods {
+		methods[method.Name.Lexeme] = NewLoxFunction(method, i.environment, method.Name.Lexeme == "init")
+	}
+	class := NewLoxClass(stmt.Name.Lexeme, superclass, methods)
+
+	if superclass != nil {
+		i.environment = i.environment.enclosing
+	}
+
+	return nil, i.environment.Assign(stmt.Name, class)
+}
+
+func (i *Interpreter) VisitExpressionStmt(stmt *ast.Expression) (any, error) {
+	_, err := i.evaluate(stmt.Expression)
+	return nil, err
+}
+
+func (i *Interpreter) VisitFunctionStmt(stmt *ast.Function) (any, error) {
+	if err := i.allocate(stmt.Name, object.FunctionSize); err != nil {
+		return nil, err
+	}
+	function := NewLoxFunction(stmt, i.environment, false)
+	i.environment.Define(stmt.Name.Lexeme, function)
+	return nil, nil
+}
+
+func (i *Interpreter) VisitIfStmt(stmt *ast.If) (any, error) {
+	condition, err := i.evaluate(stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if object.IsTruthy(condition) {
+		return nil, i.execute(stmt.ThenBranch)
+	} else if stmt.ElseBranch != nil {
+		return nil, i.execute(stmt.ElseBranch)
+	}
+	return nil, nil
+}
 
-func (i *Interpreter) Interpret(expr ast.Expr) {
-	value, err := i.evaluate(expr)
+func (i *Interpreter) VisitPrintStmt(stmt *ast.Print) (any, error) {
+	value, err := i.evaluate(stmt.Expression)
 	if err != nil {
-		if runtimeErr, ok := err.(errors.RuntimeError); ok {
-			errors.ReportRuntimeError(runtimeErr)
-		} else {
-			panic(err)
+		return nil, err
+	}
+	fmt.Fprintln(i.out, object.Stringify(value))
+	return nil, nil
+}
+
+func (i *Interpreter) VisitReturnStmt(stmt *ast.Return) (any, error) {
+	var value any
+	if stmt.Value != nil {
+		var err error
+		value, err = i.evaluate(stmt.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, returnValue{Value: value}
+}
+
+func (i *Interpreter) VisitVarStmt(stmt *ast.Var) (any, error) {
+	var value any
+	if stmt.Initializer != nil {
+		var err error
+		value, err = i.evaluate(stmt.Initializer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	i.environment.Define(stmt.Name.Lexeme, value)
+	return nil, nil
+}
+
+func (i *Interpreter) VisitWhileStmt(stmt *ast.While) (any, error) {
+	for {
+		condition, err := i.evaluate(stmt.Condition)
+		if err != nil {
+			return nil, err
+		}
+		if !object.IsTruthy(condition) {
+			return nil, nil
+		}
+
+		if err := i.execute(stmt.Body); err != nil {
+			return nil, err
+		}
+		if err := i.step(stmt.Keyword); err != nil {
+			return nil, err
 		}
 	}
-	fmt.Println(stringify(value))
+}
+
+func (i *Interpreter) VisitAssignExpr(expr *ast.Assign) (any, error) {
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if distance, ok := i.locals[expr]; ok {
+		i.environment.AssignAt(distance, expr.Name, value)
+	} else if err := i.globals.Assign(expr.Name, value); err != nil {
+		return nil, err
+	}
+	return value, nil
 }
 
 func (i *Interpreter) VisitBinaryExpr(expr *ast.Binary) (any, error) {
@@ -39,9 +313,9 @@ func (i *Interpreter) VisitBinaryExpr(expr *ast.Binary) (any, error) {
 
 	switch expr.Operator.TokenType {
 	case token.EQUAL_EQUAL:
-		return isEqual(left, right), nil
+		return object.Equal(left, right), nil
 	case token.BANG_EQUAL:
-		return !isEqual(left, right), nil
+		return !object.Equal(left, right), nil
 	}
 
 	switch expr.Operator.TokenType {
@@ -53,6 +327,9 @@ func (i *Interpreter) VisitBinaryExpr(expr *ast.Binary) (any, error) {
 		}
 		if l, okL := left.(string); okL {
 			if r, okR := right.(string); okR {
+				if err := i.allocate(expr.Operator, len(l)+len(r)); err != nil {
+					return nil, err
+				}
 				return l + r, nil
 			}
 		}
@@ -87,14 +364,359 @@ func (i *Interpreter) VisitBinaryExpr(expr *ast.Binary) (any, error) {
 	panic("unreachable code in VisitBinaryExpr")
 }
 
+func (i *Interpreter) VisitCallExpr(expr *ast.Call) (any, error) {
+	callee, err := i.evaluate(expr.Callee)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]any, 0, len(expr.Arguments))
+	for _, argument := range expr.Arguments {
+		arg, err := i.evaluate(argument)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if err := i.step(expr.Paren); err != nil {
+		return nil, err
+	}
+
+	function, ok := callee.(LoxCallable)
+	if !ok {
+		return nil, errors.RuntimeError{
+			Token:   expr.Paren,
+			Message: "Can only call functions and classes.",
+		}
+	}
+	if len(args) != function.Arity() {
+		return nil, errors.RuntimeError{
+			Token:   expr.Paren,
+			Message: fmt.Sprintf("Expected %d arguments but got %d.", function.Arity(), len(args)),
+		}
+	}
+	if len(i.frames) >= i.maxDepth {
+		return nil, i.withTrace(errors.RuntimeError{Token: expr.Paren, Message: "Stack overflow."})
+	}
+	if _, ok := function.(*LoxClass); ok {
+		if err := i.allocate(expr.Paren, object.InstanceSize); err != nil {
+			return nil, err
+		}
+	}
+
+	i.frames = append(i.frames, errors.Frame{Function: calleeName(function), CallSite: expr.Paren.Span})
+	defer func() { i.frames = i.frames[:len(i.frames)-1] }()
+
+	var value any
+	if fn, ok := function.(*NativeFunction); ok {
+		// Natives charge what they allocate at the call site.
+		value, err = fn.Invoke(allocator{i, expr.Paren}, args)
+	} else {
+		value, err = function.Call(i, args)
+	}
+	if err == nil {
+		return value, nil
+	}
+	runtimeErr, ok := err.(errors.RuntimeError)
+	if !ok {
+		// Errors from native functions are reported at the call site.
+		runtimeErr = errors.RuntimeError{Token: expr.Paren, Message: err.Error()}
+	}
+	return nil, i.withTrace(runtimeErr)
+}
+
+// step is called at every call and loop back-edge to enforce the context
+// and the step budget.
+func (i *Interpreter) step(at token.Token) error {
+	i.steps++
+	if i.budget > 0 && i.steps > i.budget {
+		return i.withTrace(errors.RuntimeError{
+			Token:   at,
+			Message: "Step budget exceeded.",
+			Cause:   errors.ErrBudgetExceeded,
+		})
+	}
+	select {
+	case <-i.ctx.Done():
+		return i.withTrace(errors.RuntimeError{
+			Token:   at,
+			Message: "Execution canceled.",
+			Cause:   fmt.Errorf("%w: %w", errors.ErrCanceled, i.ctx.Err()),
+		})
+	default:
+		return nil
+	}
+}
+
+// allocator charges the allocations of a native function called at at.
+type allocator struct {
+	interp *Interpreter
+	at     token.Token
+}
+
+func (a allocator) Allocate(size int) error {
+	return a.interp.allocate(a.at, size)
+}
+
+// allocate charges size bytes against the memory limit.
+func (i *Interpreter) allocate(at token.Token, size int) error {
+	i.allocated += size
+	if i.memoryLimit > 0 && i.allocated > i.memoryLimit {
+		return i.withTrace(errors.RuntimeError{
+			Token:   at,
+			Message: "Memory limit exceeded.",
+			Cause:   errors.ErrMemoryLimit,
+		})
+	}
+	return nil
+}
+
+// withTrace attaches the current call stack to err, unless a deeper call
+// already did.
+func (i *Interpreter) withTrace(err errors.RuntimeError) errors.RuntimeError {
+	if err.Trace == nil && len(i.frames) > 0 {
+		err.Trace = slices.Clone(i.frames)
+	}
+	return err
+}
+
+func calleeName(callee LoxCallable) string {
+	switch c := callee.(type) {
+	case *LoxFunction:
+		return c.declaration.Name.Lexeme
+	case *LoxClass:
+		return c.Name
+	case *NativeFunction:
+		return c.Name()
+	default:
+		return fmt.Sprint(callee)
+	}
+}
+
+func (i *Interpreter) VisitGetExpr(expr *ast.Get) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	switch object := object.(type) {
+	case *LoxInstance:
+		return object.Get(expr.Name)
+	case native.LoxObject:
+		return getObjectProperty(object, expr.Name)
+	}
+
+	return nil, errors.RuntimeError{
+		Token:   expr.Name,
+		Message: "Only instances have properties.",
+	}
+}
+
+func getObjectProperty(container native.LoxObject, name token.Token) (any, error) {
+	value, ok := object.GetProperty(container, name.Lexeme)
+	if !ok {
+		return nil, errors.RuntimeError{
+			Token:   name,
+			Message: fmt.Sprintf("Undefined property '%s'.", name.Lexeme),
+		}
+	}
+	if function, ok := value.(*native.Function); ok {
+		return &NativeFunction{function}, nil
+	}
+	return value, nil
+}
+
 func (i *Interpreter) VisitGroupingExpr(expr *ast.Grouping) (any, error) {
-	return i.evaluate(expr)
+	return i.evaluate(expr.Expression)
+}
+
+func (i *Interpreter) VisitIndexExpr(expr *ast.Index) (any, error) {
+	container, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := object.Index(container, index)
+	if err != nil {
+		return nil, errors.RuntimeError{Token: expr.Bracket, Message: err.Error()}
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitIndexSetExpr(expr *ast.IndexSet) (any, error) {
+	container, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if object.AddsEntry(container, index) {
+		if err := i.allocate(expr.Bracket, object.EntrySize); err != nil {
+			return nil, err
+		}
+	}
+	if err := object.SetIndex(container, index, value); err != nil {
+		return nil, errors.RuntimeError{Token: expr.Bracket, Message: err.Error()}
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitListLiteralExpr(expr *ast.ListLiteral) (any, error) {
+	elements := make([]any, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		value, err := i.evaluate(element)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, value)
+	}
+
+	if err := i.allocate(expr.Bracket, object.ListSize+len(elements)*object.ElementSize); err != nil {
+		return nil, err
+	}
+	return object.NewList(elements), nil
 }
 
 func (i *Interpreter) VisitLiteralExpr(expr *ast.Literal) (any, error) {
 	return expr.Value, nil
 }
 
+// VisitLogicalExpr short-circuits and returns the operand that decided the
+// result rather than a bool, e.g. `nil or "yes"` is "yes".
+func (i *Interpreter) VisitLogicalExpr(expr *ast.Logical) (any, error) {
+	left, err := i.evaluate(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Operator.TokenType == token.OR {
+		if object.IsTruthy(left) {
+			return left, nil
+		}
+	} else {
+		if !object.IsTruthy(left) {
+			return left, nil
+		}
+	}
+
+	return i.evaluate(expr.Right)
+}
+
+func (i *Interpreter) VisitMapLiteralExpr(expr *ast.MapLiteral) (any, error) {
+	entries := make([]any, 0, 2*len(expr.Keys))
+	for n, key := range expr.Keys {
+		for _, entryExpr := range []ast.Expr{key, expr.Values[n]} {
+			value, err := i.evaluate(entryExpr)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, value)
+		}
+	}
+
+	if err := i.allocate(expr.Brace, object.MapSize+len(expr.Keys)*object.EntrySize); err != nil {
+		return nil, err
+	}
+	m := object.NewMap()
+	for n := 0; n < len(entries); n += 2 {
+		if err := m.Store(entries[n], entries[n+1]); err != nil {
+			return nil, errors.RuntimeError{Token: expr.Brace, Message: err.Error()}
+		}
+	}
+	return m, nil
+}
+
+func (i *Interpreter) VisitSetExpr(expr *ast.Set) (any, error) {
+	container, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container.(type) {
+	case *LoxInstance, native.LoxObject:
+	default:
+		return nil, errors.RuntimeError{
+			Token:   expr.Name,
+			Message: "Only instances have fields.",
+		}
+	}
+
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	if instance, ok := container.(*LoxInstance); ok {
+		// Only new fields grow the instance.
+		if _, ok := instance.fields[expr.Name.Lexeme]; !ok {
+			if err := i.allocate(expr.Name, object.FieldSize); err != nil {
+				return nil, err
+			}
+		}
+		instance.Set(expr.Name, value)
+		return value, nil
+	}
+	if err := container.(native.LoxObject).Set(expr.Name.Lexeme, value); err != nil {
+		return nil, errors.RuntimeError{Token: expr.Name, Message: err.Error()}
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitSliceExpr(expr *ast.Slice) (any, error) {
+	container, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	var bounds [2]any
+	for n, bound := range []ast.Expr{expr.Start, expr.End} {
+		if bound == nil {
+			continue
+		}
+		if bounds[n], err = i.evaluate(bound); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := object.Slice(container, bounds[0], bounds[1])
+	if err != nil {
+		return nil, errors.RuntimeError{Token: expr.Bracket, Message: err.Error()}
+	}
+	if err := i.allocate(expr.Bracket, object.SizeOf(value)); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitSuperExpr(expr *ast.Super) (any, error) {
+	distance := i.locals[expr]
+	superclass := i.environment.GetAt(distance, "super").(*LoxClass)
+	// "this" always lives in the scope just inside the one holding "super".
+	object := i.environment.GetAt(distance-1, "this").(*LoxInstance)
+
+	method, ok := superclass.FindMethod(expr.Method.Lexeme)
+	if !ok {
+		return nil, errors.RuntimeError{
+			Token:   expr.Method,
+			Message: "Undefined property '" + expr.Method.Lexeme + "'.",
+		}
+	}
+	return method.Bind(object), nil
+}
+
+func (i *Interpreter) VisitThisExpr(expr *ast.This) (any, error) {
+	return i.lookUpVariable(expr.Keyword, expr)
+}
+
 func (i *Interpreter) VisitUnaryExpr(expr *ast.Unary) (any, error) {
 	right, err := i.evaluate(expr.Right)
 	if err != nil {
@@ -103,9 +725,9 @@ func (i *Interpreter) VisitUnaryExpr(expr *ast.Unary) (any, error) {
 
 	switch expr.Operator.TokenType {
 	case token.BANG:
-		return !isTruthy(right), nil
+		return !object.IsTruthy(right), nil
 	case token.MINUS:
-		err := checkUnaryNumberOperand(expr.Operator, expr.Right)
+		err := checkUnaryNumberOperand(expr.Operator, right)
 		if err != nil {
 			return nil, err
 		}
@@ -119,23 +741,37 @@ func (i *Interpreter) VisitUnaryExpr(expr *ast.Unary) (any, error) {
 	panic(fmt.Sprintf("Unknown unary operator: %s", expr.Operator))
 }
 
+func (i *Interpreter) VisitVariableExpr(expr *ast.Variable) (any, error) {
+	return i.lookUpVariable(expr.Name, expr)
+}
+
+func (i *Interpreter) lookUpVariable(name token.Token, expr ast.Expr) (any, error) {
+	if distance, ok := i.locals[expr]; ok {
+		return i.environment.GetAt(distance, name.Lexeme), nil
+	}
+	return i.globals.Get(name)
+}
+
 func (i *Interpreter) evaluate(expr ast.Expr) (any, error) {
 	return expr.Accept(i)
 }
 
-func isTruthy(value any) bool {
-	if value == nil {
-		return false
-	}
-	if val, ok := value.(bool); ok {
-		return !val
-	}
-	return true
+func (i *Interpreter) execute(stmt ast.Stmt) error {
+	_, err := stmt.Accept(i)
+	return err
 }
 
-func isEqual(a, b any) bool {
-	// a == b panics if a and b are non-comparables
-	return reflect.DeepEqual(a, b)
+func (i *Interpreter) executeBlock(stmts []ast.Stmt, environment *Environment) error {
+	previous := i.environment
+	defer func() { i.environment = previous }()
+
+	i.environment = environment
+	for _, stmt := range stmts {
+		if err := i.execute(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func checkUnaryNumberOperand(operator token.Token, operand any) error {
@@ -163,17 +799,3 @@ func checkBinaryNumberOperand(operator token.Token, leftOperand, rightOperand an
 	}
 	return nil
 }
-
-func stringify(value any) string {
-	if value == nil {
-		return "nil"
-	}
-	if v, ok := value.(float64); ok {
-		str := strconv.FormatFloat(v, 'f', -1, 64)
-		if strings.HasSuffix(str, ".0") {
-			str = strings.ReplaceAll(str, ".0", "")
-		}
-		return str
-	}
-	return fmt.Sprint(value)
-}
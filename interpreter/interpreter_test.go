@@ -0,0 +1,49 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/parser"
+	"github.com/anwprath/glox/resolver"
+	"github.com/anwprath/glox/scanner"
+	"github.com/anwprath/glox/source"
+)
+
+// run resolves and interprets src on i, as a REPL line would.
+func run(t *testing.T, i *Interpreter, src string) {
+	t.Helper()
+	reporter := &errors.Collector{}
+	sc := scanner.New(source.NewFileSet(), "test", src, reporter)
+	stmts, err := parser.New(sc.ScanTokens(), reporter).Parse()
+	if err == nil {
+		err = resolver.New(i, reporter).Resolve(stmts)
+	}
+	if err == nil {
+		err = i.Interpret(stmts)
+	}
+	if err != nil {
+		t.Fatalf("%q: %v %v", src, err, reporter.Diagnostics)
+	}
+}
+
+func TestTopLevelLocalsAreForgotten(t *testing.T) {
+	var out strings.Builder
+	i := New(&errors.Collector{})
+	i.SetOutput(&out)
+
+	run(t, i, `var f; { var a = "kept"; fun g() { print a; } f = g; }`)
+	for range 10 {
+		run(t, i, `{ var b = 1; var c = b + b; print c; }`)
+	}
+	run(t, i, `f();`)
+
+	// Only the reference to a in g's body is still needed.
+	if len(i.locals) != 1 {
+		t.Errorf("got %d locals, want 1", len(i.locals))
+	}
+	if want := strings.Repeat("2\n", 10) + "kept\n"; out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,225 @@
+// Package native lets host Go code provide functions and objects to Lox.
+package native
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Function is a Go function exposed to Lox scripts.
+type Function struct {
+	name  string
+	arity int
+	fn    func(alloc Allocator, args []any) (any, error)
+}
+
+// Allocator is charged for memory a native function allocates on behalf of
+// the calling script, such as elements appended to a list. Allocate fails
+// once the script exceeds its memory limit; the function should then return
+// that error without allocating.
+type Allocator interface {
+	Allocate(size int) error
+}
+
+// unlimited is the Allocator used when the caller provides none.
+type unlimited struct{}
+
+func (unlimited) Allocate(size int) error {
+	return nil
+}
+
+// New wraps fn, which receives exactly arity Lox values. An error returned
+// by fn becomes a Lox runtime error at the call site.
+func New(name string, arity int, fn func(args []any) (any, error)) *Function {
+	return NewAllocating(name, arity, func(alloc Allocator, args []any) (any, error) {
+		return fn(args)
+	})
+}
+
+// NewAllocating is New for functions whose allocations count against the
+// calling script's memory limit.
+func NewAllocating(name string, arity int, fn func(alloc Allocator, args []any) (any, error)) *Function {
+	return &Function{name: name, arity: arity, fn: fn}
+}
+
+func (f *Function) Name() string {
+	return f.name
+}
+
+func (f *Function) Arity() int {
+	return f.arity
+}
+
+// Invoke calls the function, charging its allocations to alloc, which may be
+// nil. The caller has already checked the number of arguments. A panic in
+// the Go code is recovered and returned as an error, so that a faulty native
+// cannot take down the host.
+func (f *Function) Invoke(alloc Allocator, args []any) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("Native function '%s' failed: %v.", f.name, r)
+		}
+	}()
+	if alloc == nil {
+		alloc = unlimited{}
+	}
+	return f.fn(alloc, args)
+}
+
+func (f *Function) String() string {
+	return "<native fn>"
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// Wrap turns an ordinary Go function into a Function using reflection, e.g.
+// func(float64, string) (bool, error). Parameters may be numbers, strings,
+// bools or interfaces; results may be a value, an error, or a value followed
+// by an error. Arguments of the wrong Lox type are rejected with an error
+// naming the offending argument.
+func Wrap(name string, fn any) (*Function, error) {
+	rv := reflect.ValueOf(fn)
+	rt := rv.Type()
+	if rt.Kind() != reflect.Func {
+		return nil, fmt.Errorf("native: %s is a %s, not a function", name, rt)
+	}
+	if rt.IsVariadic() {
+		return nil, fmt.Errorf("native: %s: variadic functions are not supported", name)
+	}
+	for i := range rt.NumIn() {
+		if !isSupportedParam(rt.In(i)) {
+			return nil, fmt.Errorf("native: %s: unsupported parameter type %s", name, rt.In(i))
+		}
+	}
+	switch {
+	case rt.NumOut() > 2,
+		rt.NumOut() == 2 && rt.Out(1) != errorType,
+		rt.NumOut() == 2 && rt.Out(0) == errorType:
+		return nil, fmt.Errorf("native: %s: results must be (), (T), (error) or (T, error)", name)
+	}
+
+	return New(name, rt.NumIn(), func(args []any) (any, error) {
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			value, err := fromLox(arg, rt.In(i))
+			if err != nil {
+				return nil, fmt.Errorf("Argument %d to '%s' %s.", i+1, name, err)
+			}
+			in[i] = value
+		}
+
+		out := rv.Call(in)
+		if len(out) > 0 && rt.Out(len(out)-1) == errorType {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, err
+			}
+			out = out[:len(out)-1]
+		}
+		if len(out) == 0 {
+			return nil, nil
+		}
+		value, err := ToLox(out[0].Interface())
+		if err != nil && rt.Out(0).Kind() == reflect.Interface {
+			// Most likely a Lox value handed back to the script.
+			return out[0].Interface(), nil
+		}
+		return value, err
+	}), nil
+}
+
+func isSupportedParam(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// fromLox converts a Lox value to the Go type t. The error completes the
+// sentence "Argument N to 'name' ...".
+func fromLox(value any, t reflect.Type) (reflect.Value, error) {
+	switch t.Kind() {
+	case reflect.Interface:
+		if value == nil {
+			return reflect.Zero(t), nil
+		}
+		if !reflect.TypeOf(value).AssignableTo(t) {
+			return reflect.Value{}, fmt.Errorf("must be a %s", t)
+		}
+		return reflect.ValueOf(value).Convert(t), nil
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return reflect.ValueOf(b).Convert(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("must be a boolean")
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("must be a string")
+	}
+
+	n, ok := value.(float64)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("must be a number")
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(n).Convert(t), nil
+	}
+	if math.IsInf(n, 0) || n != math.Trunc(n) {
+		return reflect.Value{}, fmt.Errorf("must be an integer")
+	}
+	// Compare before converting: converting a float outside the range of
+	// the integer type is undefined.
+	v := reflect.New(t).Elem()
+	limit := math.Ldexp(1, t.Bits())
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 || n >= limit {
+			return reflect.Value{}, fmt.Errorf("is out of range")
+		}
+		v.SetUint(uint64(n))
+	default:
+		if n < -limit/2 || n >= limit/2 {
+			return reflect.Value{}, fmt.Errorf("is out of range")
+		}
+		v.SetInt(int64(n))
+	}
+	return v, nil
+}
+
+// ToLox converts a Go value to the Lox value representing it: numbers
+// become float64, and nil, bools, strings, Functions and LoxObjects map to
+// themselves. Other functions are wrapped with Wrap and pointers to structs
+// with Reflect.
+func ToLox(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, bool, string, float64, *Function, LoxObject:
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.Elem().Kind() == reflect.Struct {
+			return Reflect(value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	case reflect.Float32:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Func:
+		return Wrap("<native fn>", value)
+	}
+	return nil, fmt.Errorf("native: unsupported Go value of type %T", value)
+}
@@ -0,0 +1,97 @@
+package native
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestInvokeRecoversPanics(t *testing.T) {
+	wrapped, err := Wrap("index", func(s string, i int) string {
+		return s[i : i+1]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := New("boom", 0, func(args []any) (any, error) {
+		panic("boom")
+	})
+
+	for _, call := range []struct {
+		fn   *Function
+		args []any
+	}{
+		{wrapped, []any{"abc", 10.0}},
+		{raw, nil},
+	} {
+		result, err := call.fn.Invoke(nil, call.args)
+		if err == nil || result != nil {
+			t.Errorf("%s: got %v, %v; want an error", call.fn.Name(), result, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "'"+call.fn.Name()+"' failed") {
+			t.Errorf("%s: error %q does not name the function", call.fn.Name(), err)
+		}
+	}
+}
+
+func TestWrapConvertsArguments(t *testing.T) {
+	var got []any
+	wrap := func(name string, fn any) *Function {
+		function, err := Wrap(name, fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return function
+	}
+	i64 := wrap("i64", func(n int64) { got = append(got, n) })
+	u64 := wrap("u64", func(n uint64) { got = append(got, n) })
+	i8 := wrap("i8", func(n int8) { got = append(got, n) })
+	u8 := wrap("u8", func(n uint8) { got = append(got, n) })
+	str := wrap("str", func(s string) { got = append(got, s) })
+
+	tests := []struct {
+		fn      *Function
+		arg     any
+		want    any
+		wantErr string
+	}{
+		{i64, 42.0, int64(42), ""},
+		{i64, -9007199254740992.0, int64(-9007199254740992), ""},
+		{i64, math.Ldexp(-1, 63), int64(math.MinInt64), ""},
+		{i64, math.Ldexp(1, 63), nil, "Argument 1 to 'i64' is out of range."},
+		{i64, 1e21, nil, "Argument 1 to 'i64' is out of range."},
+		{i64, math.Inf(1), nil, "Argument 1 to 'i64' must be an integer."},
+		{i64, math.Inf(-1), nil, "Argument 1 to 'i64' must be an integer."},
+		{i64, math.NaN(), nil, "Argument 1 to 'i64' must be an integer."},
+		{i64, 1.5, nil, "Argument 1 to 'i64' must be an integer."},
+		{i64, "1", nil, "Argument 1 to 'i64' must be a number."},
+		{u64, 1e42, nil, "Argument 1 to 'u64' is out of range."},
+		{u64, math.Ldexp(1, 64), nil, "Argument 1 to 'u64' is out of range."},
+		{u64, -1.0, nil, "Argument 1 to 'u64' is out of range."},
+		{u64, math.Ldexp(1, 63), uint64(1) << 63, ""},
+		{i8, 127.0, int8(127), ""},
+		{i8, -128.0, int8(-128), ""},
+		{i8, 128.0, nil, "Argument 1 to 'i8' is out of range."},
+		{u8, 255.0, uint8(255), ""},
+		{u8, 256.0, nil, "Argument 1 to 'u8' is out of range."},
+		{str, 1.0, nil, "Argument 1 to 'str' must be a string."},
+		{str, nil, nil, "Argument 1 to 'str' must be a string."},
+	}
+	for _, tt := range tests {
+		got = nil
+		_, err := tt.fn.Invoke(nil, []any{tt.arg})
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s(%v): got error %v, want %q", tt.fn.Name(), tt.arg, err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("%s(%v): called with %v despite the error", tt.fn.Name(), tt.arg, got)
+			}
+			continue
+		}
+		if err != nil || len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s(%v): got %v, %v; want %v", tt.fn.Name(), tt.arg, got, err, tt.want)
+		}
+	}
+}
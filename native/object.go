@@ -0,0 +1,159 @@
+package native
+
+import (
+	"fmt"
+	"reflect"
+	"unicode"
+)
+
+// LoxObject is a host value that scripts can use like an instance: read and
+// write its properties with obj.name and call its methods with
+// obj.method(args). Implement it directly for full control, or use Reflect.
+type LoxObject interface {
+	// Get returns the property called name, or false if there is none.
+	Get(name string) (any, bool)
+	// Set assigns a property. The error becomes a Lox runtime error.
+	Set(name string, value any) error
+	// Methods returns the callable methods, already bound to the object.
+	Methods() map[string]*Function
+}
+
+var loxObjectType = reflect.TypeFor[LoxObject]()
+
+// Reflect exposes a pointer to a struct as a LoxObject. Exported fields of
+// supported types become properties and exported methods with signatures
+// accepted by Wrap become methods. Names start with a lower case letter in
+// Lox (MaxRetries is maxRetries, see loxName) unless renamed with a `lox:"name"` tag;
+// `lox:"-"` hides a field.
+func Reflect(ptr any) (LoxObject, error) {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("native: %T is not a pointer to a struct", ptr)
+	}
+
+	object := &reflectObject{
+		value:    rv,
+		fields:   make(map[string]int),
+		methods:  make(map[string]*Function),
+		wrappers: make(map[int]wrapper),
+	}
+
+	st := rv.Elem().Type()
+	for i := range st.NumField() {
+		field := st.Field(i)
+		if !field.IsExported() || !isSupportedField(field.Type) {
+			continue
+		}
+		name := loxName(field.Name)
+		if tag, ok := field.Tag.Lookup("lox"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		object.fields[name] = i
+	}
+
+	for i := range rv.NumMethod() {
+		method := rv.Type().Method(i)
+		name := loxName(method.Name)
+		if function, err := Wrap(name, rv.Method(i).Interface()); err == nil {
+			object.methods[name] = function
+		}
+	}
+	return object, nil
+}
+
+func isSupportedField(t reflect.Type) bool {
+	if isSupportedParam(t) || t.Implements(loxObjectType) {
+		return true
+	}
+	return t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct
+}
+
+// loxName lower-cases the leading capitals of a Go name, keeping the last
+// one when it starts the next word: Name is name, DB is db and URLPath is
+// urlPath.
+func loxName(goName string) string {
+	runes := []rune(goName)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		n--
+	}
+	for i := range n {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+type reflectObject struct {
+	value   reflect.Value
+	fields  map[string]int
+	methods map[string]*Function
+	// wrappers holds the object last returned for each pointer field, so
+	// that reading the field again yields the same, equal object.
+	wrappers map[int]wrapper
+}
+
+type wrapper struct {
+	ptr    any
+	object any
+}
+
+func (o *reflectObject) Get(name string) (any, bool) {
+	index, ok := o.fields[name]
+	if !ok {
+		return nil, false
+	}
+
+	field := o.value.Elem().Field(index)
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil, true
+		}
+		if w, ok := o.wrappers[index]; ok && w.ptr == field.Interface() {
+			return w.object, true
+		}
+	}
+	value, err := ToLox(field.Interface())
+	if err != nil {
+		// Interfaces may hold anything, e.g. a value a script stored.
+		return field.Interface(), true
+	}
+	if field.Kind() == reflect.Pointer {
+		o.wrappers[index] = wrapper{ptr: field.Interface(), object: value}
+	}
+	return value, true
+}
+
+func (o *reflectObject) Set(name string, value any) error {
+	index, ok := o.fields[name]
+	if !ok {
+		return fmt.Errorf("Undefined property '%s'.", name)
+	}
+
+	field := o.value.Elem().Field(index)
+	if !isSupportedParam(field.Type()) {
+		return fmt.Errorf("Property '%s' is read-only.", name)
+	}
+	converted, err := fromLox(value, field.Type())
+	if err != nil {
+		return fmt.Errorf("Property '%s' %s.", name, err)
+	}
+	field.Set(converted)
+	return nil
+}
+
+func (o *reflectObject) Methods() map[string]*Function {
+	return o.methods
+}
+
+func (o *reflectObject) String() string {
+	if stringer, ok := o.value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return o.value.Elem().Type().Name() + " object"
+}
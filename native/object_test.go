@@ -0,0 +1,127 @@
+package native
+
+import (
+	"testing"
+)
+
+type db struct {
+	URL string
+}
+
+type config struct {
+	Name       string
+	MaxRetries int64
+	Ratio      float64 `lox:"scale"`
+	Secret     string  `lox:"-"`
+	DB         *db
+	hidden     int
+}
+
+func (c *config) Describe(prefix string) string {
+	return prefix + c.Name
+}
+
+func (c *config) Retry() error {
+	c.MaxRetries--
+	return nil
+}
+
+func newConfig(t *testing.T) (*config, LoxObject) {
+	t.Helper()
+	cfg := &config{Name: "svc", MaxRetries: 3, Ratio: 0.5, Secret: "s", DB: &db{URL: "x"}}
+	object, err := Reflect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg, object
+}
+
+func TestReflectGet(t *testing.T) {
+	_, object := newConfig(t)
+	for _, tt := range []struct {
+		name string
+		want any
+	}{
+		{"name", "svc"},
+		{"maxRetries", 3.0},
+		{"scale", 0.5},
+	} {
+		if got, ok := object.Get(tt.name); !ok || got != tt.want {
+			t.Errorf("Get(%q) = %v, %v; want %v", tt.name, got, ok, tt.want)
+		}
+	}
+	for _, name := range []string{"ratio", "secret", "Secret", "hidden", "MaxRetries"} {
+		if got, ok := object.Get(name); ok {
+			t.Errorf("Get(%q) = %v, want no such property", name, got)
+		}
+	}
+}
+
+func TestReflectPointerFieldIdentity(t *testing.T) {
+	cfg, object := newConfig(t)
+	first, _ := object.Get("db")
+	second, _ := object.Get("db")
+	if first != second {
+		t.Error("reading db twice gave different objects")
+	}
+	url, _ := first.(LoxObject).Get("url")
+	if url != "x" {
+		t.Errorf("db.url = %v, want x", url)
+	}
+
+	cfg.DB = &db{URL: "y"}
+	third, _ := object.Get("db")
+	if third == first {
+		t.Error("db still reads as the old object after the host replaced it")
+	}
+	cfg.DB = nil
+	if got, ok := object.Get("db"); !ok || got != nil {
+		t.Errorf("nil db = %v, %v; want nil", got, ok)
+	}
+}
+
+func TestReflectSet(t *testing.T) {
+	cfg, object := newConfig(t)
+	if err := object.Set("maxRetries", 7.0); err != nil || cfg.MaxRetries != 7 {
+		t.Errorf("Set(maxRetries, 7) = %v; field is %d", err, cfg.MaxRetries)
+	}
+	if err := object.Set("scale", 2.0); err != nil || cfg.Ratio != 2 {
+		t.Errorf("Set(scale, 2) = %v; field is %v", err, cfg.Ratio)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{"maxRetries", 1e23, "Property 'maxRetries' is out of range."},
+		{"maxRetries", 1.5, "Property 'maxRetries' must be an integer."},
+		{"maxRetries", "3", "Property 'maxRetries' must be a number."},
+		{"name", 1.0, "Property 'name' must be a string."},
+		{"db", nil, "Property 'db' is read-only."},
+		{"secret", "t", "Undefined property 'secret'."},
+	} {
+		if err := object.Set(tt.name, tt.value); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("Set(%q, %v) = %v, want %q", tt.name, tt.value, err, tt.wantErr)
+		}
+	}
+	if cfg.MaxRetries != 7 || cfg.Name != "svc" || cfg.Secret != "s" {
+		t.Errorf("failed sets changed the struct: %+v", cfg)
+	}
+}
+
+func TestReflectMethods(t *testing.T) {
+	cfg, object := newConfig(t)
+	methods := object.Methods()
+
+	got, err := methods["describe"].Invoke(nil, []any{"name: "})
+	if err != nil || got != "name: svc" {
+		t.Errorf("describe() = %v, %v; want name: svc", got, err)
+	}
+	if _, err := methods["retry"].Invoke(nil, nil); err != nil || cfg.MaxRetries != 2 {
+		t.Errorf("retry() = %v; maxRetries is %d, want 2", err, cfg.MaxRetries)
+	}
+	if _, err := methods["describe"].Invoke(nil, []any{1.0}); err == nil {
+		t.Error("describe(1) succeeded, want an argument error")
+	}
+}
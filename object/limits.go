@@ -0,0 +1,29 @@
+package object
+
+// DefaultMaxCallDepth is the call depth beyond which scripts fail with
+// "Stack overflow." unless a backend is configured otherwise.
+const DefaultMaxCallDepth = 1024
+
+// Approximate sizes in bytes of the objects scripts allocate, charged
+// against a backend's memory limit. Strings are charged their length.
+const (
+	FunctionSize = 64
+	ClassSize    = 64
+	InstanceSize = 48
+	FieldSize    = 32
+	ListSize     = 48
+	ElementSize  = 16
+	MapSize      = 64
+	EntrySize    = 48
+)
+
+// SizeOf approximates the size of a string or list.
+func SizeOf(value any) int {
+	switch value := value.(type) {
+	case string:
+		return len(value)
+	case *List:
+		return ListSize + len(value.Elements)*ElementSize
+	}
+	return 0
+}
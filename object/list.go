@@ -0,0 +1,82 @@
+package object
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/anwprath/glox/native"
+)
+
+// List is a Lox list. Lists are mutable and compared by identity.
+type List struct {
+	Elements []any
+	// methods is built on first use, see Methods.
+	methods map[string]*native.Function
+}
+
+var _ native.LoxObject = &List{}
+
+func NewList(elements []any) *List {
+	return &List{Elements: elements}
+}
+
+func (l *List) Get(name string) (any, bool) {
+	return nil, false
+}
+
+func (l *List) Set(name string, value any) error {
+	return fmt.Errorf("Can't add properties to lists.")
+}
+
+// Methods returns push, pop, insert, remove and len bound to l. Each element
+// added by push and insert is charged ElementSize.
+func (l *List) Methods() map[string]*native.Function {
+	if l.methods == nil {
+		l.methods = map[string]*native.Function{
+			"push": native.NewAllocating("push", 1, func(alloc native.Allocator, args []any) (any, error) {
+				if err := alloc.Allocate(ElementSize); err != nil {
+					return nil, err
+				}
+				l.Elements = append(l.Elements, args[0])
+				return nil, nil
+			}),
+			"pop": native.New("pop", 0, func(args []any) (any, error) {
+				if len(l.Elements) == 0 {
+					return nil, fmt.Errorf("Can't pop from an empty list.")
+				}
+				last := l.Elements[len(l.Elements)-1]
+				l.Elements = l.Elements[:len(l.Elements)-1]
+				return last, nil
+			}),
+			"insert": native.NewAllocating("insert", 2, func(alloc native.Allocator, args []any) (any, error) {
+				// Inserting at len(l.Elements) appends.
+				i, err := checkIndex(args[0], len(l.Elements)+1, "List")
+				if err != nil {
+					return nil, err
+				}
+				if err := alloc.Allocate(ElementSize); err != nil {
+					return nil, err
+				}
+				l.Elements = slices.Insert(l.Elements, i, args[1])
+				return nil, nil
+			}),
+			"remove": native.New("remove", 1, func(args []any) (any, error) {
+				i, err := checkIndex(args[0], len(l.Elements), "List")
+				if err != nil {
+					return nil, err
+				}
+				removed := l.Elements[i]
+				l.Elements = slices.Delete(l.Elements, i, i+1)
+				return removed, nil
+			}),
+			"len": native.New("len", 0, func(args []any) (any, error) {
+				return float64(len(l.Elements)), nil
+			}),
+		}
+	}
+	return l.methods
+}
+
+func (l *List) String() string {
+	return Stringify(l)
+}
@@ -0,0 +1,129 @@
+package object
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/anwprath/glox/native"
+)
+
+// Map is a Lox map. Keys are nil, booleans, numbers or strings, compared
+// with Lox equality. Entries keep their insertion order, so that keys(),
+// values() and printing are deterministic.
+type Map struct {
+	keys   []any
+	values []any
+	// index maps each key to its position in keys and values.
+	index map[any]int
+	// methods is built on first use, see Methods.
+	methods map[string]*native.Function
+}
+
+var _ native.LoxObject = &Map{}
+
+func NewMap() *Map {
+	return &Map{index: make(map[any]int)}
+}
+
+// Len returns the number of entries.
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// Lookup returns the value stored under key.
+func (m *Map) Lookup(key any) (any, bool, error) {
+	if err := checkKey(key); err != nil {
+		return nil, false, err
+	}
+	i, ok := m.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+	return m.values[i], true, nil
+}
+
+// Store sets the value under key. New keys go after all existing ones.
+func (m *Map) Store(key, value any) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if i, ok := m.index[key]; ok {
+		m.values[i] = value
+		return nil
+	}
+	m.index[key] = len(m.keys)
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+	return nil
+}
+
+// Delete removes key and returns its value, or nil if it was absent.
+func (m *Map) Delete(key any) (any, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	i, ok := m.index[key]
+	if !ok {
+		return nil, nil
+	}
+	value := m.values[i]
+	delete(m.index, key)
+	m.keys = slices.Delete(m.keys, i, i+1)
+	m.values = slices.Delete(m.values, i, i+1)
+	for j := i; j < len(m.keys); j++ {
+		m.index[m.keys[j]] = j
+	}
+	return value, nil
+}
+
+func checkKey(key any) error {
+	switch key.(type) {
+	case nil, bool, float64, string:
+		return nil
+	}
+	return fmt.Errorf("Map keys must be strings, numbers, booleans or nil.")
+}
+
+func (m *Map) Get(name string) (any, bool) {
+	return nil, false
+}
+
+func (m *Map) Set(name string, value any) error {
+	return fmt.Errorf("Can't add properties to maps.")
+}
+
+// Methods returns keys, values, has, remove and len bound to m. The lists
+// returned by keys and values are charged like list literals.
+func (m *Map) Methods() map[string]*native.Function {
+	if m.methods == nil {
+		m.methods = map[string]*native.Function{
+			"keys": native.NewAllocating("keys", 0, func(alloc native.Allocator, args []any) (any, error) {
+				if err := alloc.Allocate(ListSize + m.Len()*ElementSize); err != nil {
+					return nil, err
+				}
+				return NewList(slices.Clone(m.keys)), nil
+			}),
+			"values": native.NewAllocating("values", 0, func(alloc native.Allocator, args []any) (any, error) {
+				if err := alloc.Allocate(ListSize + m.Len()*ElementSize); err != nil {
+					return nil, err
+				}
+				return NewList(slices.Clone(m.values)), nil
+			}),
+			"has": native.New("has", 1, func(args []any) (any, error) {
+				_, ok, err := m.Lookup(args[0])
+				return ok, err
+			}),
+			"remove": native.New("remove", 1, func(args []any) (any, error) {
+				return m.Delete(args[0])
+			}),
+			"len": native.New("len", 0, func(args []any) (any, error) {
+				return float64(m.Len()), nil
+			}),
+		}
+	}
+	return m.methods
+}
+
+func (m *Map) String() string {
+	return Stringify(m)
+}
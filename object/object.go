@@ -0,0 +1,239 @@
+// Package object implements the built-in Lox values shared by the
+// tree-walking interpreter and the VM, and the operations on them that both
+// backends must agree on.
+package object
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/anwprath/glox/native"
+)
+
+// Stringify formats a value the way print shows it.
+func Stringify(value any) string {
+	var b strings.Builder
+	format(&b, value, false, nil)
+	return b.String()
+}
+
+// format writes value to b. Strings are quoted inside collections, and
+// collections already being formatted, i.e. ones that contain themselves,
+// are shown as "[...]" or "{...}".
+func format(b *strings.Builder, value any, quote bool, seen map[any]bool) {
+	switch value := value.(type) {
+	case nil:
+		b.WriteString("nil")
+	case float64:
+		// 'f' with precision -1 never emits a trailing ".0".
+		b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	case string:
+		if quote {
+			b.WriteString(strconv.Quote(value))
+		} else {
+			b.WriteString(value)
+		}
+	case *List:
+		if seen[value] {
+			b.WriteString("[...]")
+			return
+		}
+		if seen == nil {
+			seen = make(map[any]bool)
+		}
+		seen[value] = true
+		b.WriteByte('[')
+		for i, element := range value.Elements {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			format(b, element, true, seen)
+		}
+		b.WriteByte(']')
+		delete(seen, value)
+	case *Map:
+		if seen[value] {
+			b.WriteString("{...}")
+			return
+		}
+		if seen == nil {
+			seen = make(map[any]bool)
+		}
+		seen[value] = true
+		b.WriteByte('{')
+		for i, key := range value.keys {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			format(b, key, true, seen)
+			b.WriteString(": ")
+			format(b, value.values[i], true, seen)
+		}
+		b.WriteByte('}')
+		delete(seen, value)
+	default:
+		fmt.Fprint(b, value)
+	}
+}
+
+// IsTruthy reports whether value counts as true in a condition: everything
+// except nil and false does.
+func IsTruthy(value any) bool {
+	if value == nil {
+		return false
+	}
+	if val, ok := value.(bool); ok {
+		return val
+	}
+	return true
+}
+
+// Equal reports whether two values are equal in Lox: primitives by value,
+// everything else by identity.
+func Equal(a, b any) bool {
+	// == panics for host values of incomparable types.
+	if a != nil && !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// Index evaluates object[index]. The error is the message of the runtime
+// error to raise.
+func Index(object, index any) (any, error) {
+	switch object := object.(type) {
+	case *List:
+		i, err := checkIndex(index, len(object.Elements), "List")
+		if err != nil {
+			return nil, err
+		}
+		return object.Elements[i], nil
+	case *Map:
+		value, ok, err := object.Lookup(index)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			var key strings.Builder
+			format(&key, index, true, nil)
+			return nil, fmt.Errorf("Undefined key %s.", key.String())
+		}
+		return value, nil
+	case string:
+		runes := []rune(object)
+		i, err := checkIndex(index, len(runes), "String")
+		if err != nil {
+			return nil, err
+		}
+		return string(runes[i]), nil
+	}
+	return nil, fmt.Errorf("Can only index lists, maps and strings.")
+}
+
+// SetIndex evaluates object[index] = value.
+func SetIndex(object, index, value any) error {
+	if m, ok := object.(*Map); ok {
+		return m.Store(index, value)
+	}
+	list, ok := object.(*List)
+	if !ok {
+		return fmt.Errorf("Can only assign to list elements and map entries.")
+	}
+	i, err := checkIndex(index, len(list.Elements), "List")
+	if err != nil {
+		return err
+	}
+	list.Elements[i] = value
+	return nil
+}
+
+// AddsEntry reports whether SetIndex(object, index, ...) would add a new
+// entry to a map, which is charged EntrySize.
+func AddsEntry(object, index any) bool {
+	m, ok := object.(*Map)
+	if !ok {
+		return false
+	}
+	_, found, err := m.Lookup(index)
+	return err == nil && !found
+}
+
+// Slice evaluates object[start:end], where a nil bound stands for the
+// start or end of object. Bounds past either end are clamped, so slicing
+// never fails on an integer bound.
+func Slice(object, start, end any) (any, error) {
+	switch object := object.(type) {
+	case *List:
+		from, to, err := sliceBounds(start, end, len(object.Elements))
+		if err != nil {
+			return nil, err
+		}
+		return NewList(slices.Clone(object.Elements[from:to])), nil
+	case string:
+		runes := []rune(object)
+		from, to, err := sliceBounds(start, end, len(runes))
+		if err != nil {
+			return nil, err
+		}
+		return string(runes[from:to]), nil
+	}
+	return nil, fmt.Errorf("Can only slice lists and strings.")
+}
+
+func sliceBounds(start, end any, n int) (int, int, error) {
+	from, err := sliceBound(start, 0, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := sliceBound(end, n, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	return from, max(from, to), nil
+}
+
+func sliceBound(bound any, missing, n int) (int, error) {
+	if bound == nil {
+		return missing, nil
+	}
+	f, ok := bound.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, fmt.Errorf("Slice bounds must be integers.")
+	}
+	i := int(max(min(f, float64(n)), float64(-n)))
+	if i < 0 {
+		i += n
+	}
+	return i, nil
+}
+
+// checkIndex validates index as a position in a sequence of length n.
+// Negative indices count from the end.
+func checkIndex(index any, n int, kind string) (int, error) {
+	f, ok := index.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, fmt.Errorf("Index must be an integer.")
+	}
+	i := int(f)
+	if i < 0 {
+		i += n
+	}
+	if i < 0 || i >= n {
+		return 0, fmt.Errorf("%s index out of range.", kind)
+	}
+	return i, nil
+}
+
+// GetProperty looks up name on a host object, preferring properties over
+// methods just as instance fields shadow methods.
+func GetProperty(object native.LoxObject, name string) (any, bool) {
+	if value, ok := object.Get(name); ok {
+		return value, true
+	}
+	method, ok := object.Methods()[name]
+	return method, ok
+}
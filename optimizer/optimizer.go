@@ -0,0 +1,307 @@
+package optimizer
+
+import (
+	"github.com/anwprath/glox/ast"
+	"github.com/anwprath/glox/object"
+	"github.com/anwprath/glox/token"
+)
+
+var _ ast.ExprVisitor = &Optimizer{}
+var _ ast.StmtVisitor = &Optimizer{}
+
+// Optimizer rewrites a resolved program into a cheaper one with the same
+// observable behaviour:
+//
+//   - unary and binary operators over literals are folded into a literal,
+//     unless evaluating them would raise a runtime error or allocate, as
+//     string concatenation does: folding would bypass the memory limit;
+//   - and/or with a literal left operand are reduced to the deciding side;
+//   - if and while statements with a literal condition lose their dead
+//     branch or loop;
+//   - grouping nodes are dropped, as the tree already encodes precedence.
+//
+// Every Visit method returns the node replacing the one visited; statements
+// may be replaced by nil when they are removed. Variable nodes are never
+// replaced, so bindings recorded by the resolver stay valid and the pass can
+// run after it, once every static error has been reported.
+type Optimizer struct{}
+
+func New() *Optimizer {
+	return &Optimizer{}
+}
+
+func (o *Optimizer) Optimize(stmts []ast.Stmt) []ast.Stmt {
+	return o.optimizeStmts(stmts)
+}
+
+func (o *Optimizer) VisitBlockStmt(stmt *ast.Block) (any, error) {
+	stmt.Statements = o.optimizeStmts(stmt.Statements)
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitClassStmt(stmt *ast.Class) (any, error) {
+	for _, method := range stmt.Methods {
+		method.Body = o.optimizeStmts(method.Body)
+	}
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitExpressionStmt(stmt *ast.Expression) (any, error) {
+	stmt.Expression = o.optimizeExpr(stmt.Expression)
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitFunctionStmt(stmt *ast.Function) (any, error) {
+	stmt.Body = o.optimizeStmts(stmt.Body)
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitIfStmt(stmt *ast.If) (any, error) {
+	stmt.Condition = o.optimizeExpr(stmt.Condition)
+	stmt.ThenBranch = o.optimizeBranch(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		stmt.ElseBranch = o.optimizeStmt(stmt.ElseBranch)
+	}
+
+	literal, ok := stmt.Condition.(*ast.Literal)
+	if !ok {
+		return stmt, nil
+	}
+	if object.IsTruthy(literal.Value) {
+		return stmt.ThenBranch, nil
+	}
+	// A nil ElseBranch removes the whole statement.
+	return stmt.ElseBranch, nil
+}
+
+func (o *Optimizer) VisitPrintStmt(stmt *ast.Print) (any, error) {
+	stmt.Expression = o.optimizeExpr(stmt.Expression)
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitReturnStmt(stmt *ast.Return) (any, error) {
+	if stmt.Value != nil {
+		stmt.Value = o.optimizeExpr(stmt.Value)
+	}
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitVarStmt(stmt *ast.Var) (any, error) {
+	if stmt.Initializer != nil {
+		stmt.Initializer = o.optimizeExpr(stmt.Initializer)
+	}
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitWhileStmt(stmt *ast.While) (any, error) {
+	stmt.Condition = o.optimizeExpr(stmt.Condition)
+	if literal, ok := stmt.Condition.(*ast.Literal); ok && !object.IsTruthy(literal.Value) {
+		return nil, nil
+	}
+
+	stmt.Body = o.optimizeBranch(stmt.Body)
+	return stmt, nil
+}
+
+func (o *Optimizer) VisitAssignExpr(expr *ast.Assign) (any, error) {
+	expr.Value = o.optimizeExpr(expr.Value)
+	return expr, nil
+}
+
+func (o *Optimizer) VisitBinaryExpr(expr *ast.Binary) (any, error) {
+	expr.Left = o.optimizeExpr(expr.Left)
+	expr.Right = o.optimizeExpr(expr.Right)
+
+	left, okL := expr.Left.(*ast.Literal)
+	right, okR := expr.Right.(*ast.Literal)
+	if !okL || !okR {
+		return expr, nil
+	}
+	if value, ok := foldBinary(expr.Operator.TokenType, left.Value, right.Value); ok {
+		return &ast.Literal{Value: value, Token: expr.Operator}, nil
+	}
+	return expr, nil
+}
+
+func (o *Optimizer) VisitCallExpr(expr *ast.Call) (any, error) {
+	expr.Callee = o.optimizeExpr(expr.Callee)
+	for idx, arg := range expr.Arguments {
+		expr.Arguments[idx] = o.optimizeExpr(arg)
+	}
+	return expr, nil
+}
+
+func (o *Optimizer) VisitGetExpr(expr *ast.Get) (any, error) {
+	expr.Object = o.optimizeExpr(expr.Object)
+	return expr, nil
+}
+
+func (o *Optimizer) VisitGroupingExpr(expr *ast.Grouping) (any, error) {
+	return o.optimizeExpr(expr.Expression), nil
+}
+
+func (o *Optimizer) VisitIndexExpr(expr *ast.Index) (any, error) {
+	expr.Object = o.optimizeExpr(expr.Object)
+	expr.Index = o.optimizeExpr(expr.Index)
+	return expr, nil
+}
+
+func (o *Optimizer) VisitIndexSetExpr(expr *ast.IndexSet) (any, error) {
+	expr.Object = o.optimizeExpr(expr.Object)
+	expr.Index = o.optimizeExpr(expr.Index)
+	expr.Value = o.optimizeExpr(expr.Value)
+	return expr, nil
+}
+
+func (o *Optimizer) VisitListLiteralExpr(expr *ast.ListLiteral) (any, error) {
+	for i, element := range expr.Elements {
+		expr.Elements[i] = o.optimizeExpr(element)
+	}
+	return expr, nil
+}
+
+func (o *Optimizer) VisitLiteralExpr(expr *ast.Literal) (any, error) {
+	return expr, nil
+}
+
+func (o *Optimizer) VisitLogicalExpr(expr *ast.Logical) (any, error) {
+	expr.Left = o.optimizeExpr(expr.Left)
+	expr.Right = o.optimizeExpr(expr.Right)
+
+	left, ok := expr.Left.(*ast.Literal)
+	if !ok {
+		return expr, nil
+	}
+	// The left operand decides when it is truthy for "or" and falsey for
+	// "and"; otherwise the result is whatever the right operand yields.
+	if object.IsTruthy(left.Value) == (expr.Operator.TokenType == token.OR) {
+		return left, nil
+	}
+	return expr.Right, nil
+}
+
+func (o *Optimizer) VisitMapLiteralExpr(expr *ast.MapLiteral) (any, error) {
+	for i, key := range expr.Keys {
+		expr.Keys[i] = o.optimizeExpr(key)
+		expr.Values[i] = o.optimizeExpr(expr.Values[i])
+	}
+	return expr, nil
+}
+
+func (o *Optimizer) VisitSetExpr(expr *ast.Set) (any, error) {
+	expr.Object = o.optimizeExpr(expr.Object)
+	expr.Value = o.optimizeExpr(expr.Value)
+	return expr, nil
+}
+
+func (o *Optimizer) VisitSliceExpr(expr *ast.Slice) (any, error) {
+	expr.Object = o.optimizeExpr(expr.Object)
+	if expr.Start != nil {
+		expr.Start = o.optimizeExpr(expr.Start)
+	}
+	if expr.End != nil {
+		expr.End = o.optimizeExpr(expr.End)
+	}
+	return expr, nil
+}
+
+func (o *Optimizer) VisitSuperExpr(expr *ast.Super) (any, error) {
+	return expr, nil
+}
+
+func (o *Optimizer) VisitThisExpr(expr *ast.This) (any, error) {
+	return expr, nil
+}
+
+func (o *Optimizer) VisitUnaryExpr(expr *ast.Unary) (any, error) {
+	expr.Right = o.optimizeExpr(expr.Right)
+
+	right, ok := expr.Right.(*ast.Literal)
+	if !ok {
+		return expr, nil
+	}
+	switch expr.Operator.TokenType {
+	case token.BANG:
+		return &ast.Literal{Value: !object.IsTruthy(right.Value), Token: expr.Operator}, nil
+	case token.MINUS:
+		// A non-number operand is a runtime error: leave it to the runtime.
+		if value, ok := right.Value.(float64); ok {
+			return &ast.Literal{Value: -value, Token: expr.Operator}, nil
+		}
+	}
+	return expr, nil
+}
+
+func (o *Optimizer) VisitVariableExpr(expr *ast.Variable) (any, error) {
+	return expr, nil
+}
+
+func (o *Optimizer) optimizeStmts(stmts []ast.Stmt) []ast.Stmt {
+	optimized := make([]ast.Stmt, 0, len(stmts))
+	for _, stmt := range stmts {
+		if stmt = o.optimizeStmt(stmt); stmt != nil {
+			optimized = append(optimized, stmt)
+		}
+	}
+	return optimized
+}
+
+// optimizeStmt returns nil if stmt was removed.
+func (o *Optimizer) optimizeStmt(stmt ast.Stmt) ast.Stmt {
+	replacement, _ := stmt.Accept(o)
+	if replacement == nil {
+		return nil
+	}
+	return replacement.(ast.Stmt)
+}
+
+// optimizeBranch is optimizeStmt for the body of an if or while, which must
+// not be nil: a removed body becomes an empty block.
+func (o *Optimizer) optimizeBranch(stmt ast.Stmt) ast.Stmt {
+	if optimized := o.optimizeStmt(stmt); optimized != nil {
+		return optimized
+	}
+	return &ast.Block{Statements: []ast.Stmt{}}
+}
+
+func (o *Optimizer) optimizeExpr(expr ast.Expr) ast.Expr {
+	replacement, _ := expr.Accept(o)
+	return replacement.(ast.Expr)
+}
+
+// foldBinary evaluates operator over two literal values. It reports false
+// when the operation would fail at runtime, so that the error still happens
+// there.
+func foldBinary(operator token.TokenType, left, right any) (any, bool) {
+	switch operator {
+	case token.EQUAL_EQUAL:
+		return object.Equal(left, right), true
+	case token.BANG_EQUAL:
+		return !object.Equal(left, right), true
+	}
+
+	l, okL := left.(float64)
+	r, okR := right.(float64)
+	if !okL || !okR {
+		return nil, false
+	}
+	switch operator {
+	case token.PLUS:
+		return l + r, true
+	case token.MINUS:
+		return l - r, true
+	case token.STAR:
+		return l * r, true
+	case token.SLASH:
+		return l / r, true
+	case token.LESS:
+		return l < r, true
+	case token.LESS_EQUAL:
+		return l <= r, true
+	case token.GREATER:
+		return l > r, true
+	case token.GREATER_EQUAL:
+		return l >= r, true
+	}
+	return nil, false
+}
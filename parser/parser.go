@@ -1,7 +1,7 @@
 package parser
 
 import (
-	// "errors"
+	stderrors "errors"
 	"slices"
 
 	"github.com/anwprath/glox/ast"
@@ -12,60 +12,510 @@ import (
 /**
 Grammar/Rules:
 
-	expression     → equality ;
+	program        → declaration* EOF ;
+	declaration    → classDecl
+				| funDecl
+				| varDecl
+				| statement ;
+	classDecl      → "class" IDENTIFIER ( "<" IDENTIFIER )?
+				"{" function* "}" ;
+	funDecl        → "fun" function ;
+	function       → IDENTIFIER "(" parameters? ")" block ;
+	parameters     → IDENTIFIER ( "," IDENTIFIER )* ;
+	varDecl        → "var" IDENTIFIER ( "=" expression )? ";" ;
+	statement      → exprStmt
+				| forStmt
+				| ifStmt
+				| printStmt
+				| returnStmt
+				| whileStmt
+				| block ;
+	exprStmt       → expression ";" ;
+	forStmt        → "for" "(" ( varDecl | exprStmt | ";" )
+				expression? ";"
+				expression? ")" statement ;
+	ifStmt         → "if" "(" expression ")" statement
+				( "else" statement )? ;
+	printStmt      → "print" expression ";" ;
+	returnStmt     → "return" expression? ";" ;
+	whileStmt      → "while" "(" expression ")" statement ;
+	block          → "{" declaration* "}" ;
+
+	expression     → assignment ;
+	assignment     → ( call "." )? IDENTIFIER "=" assignment
+				| call "[" expression "]" "=" assignment
+				| logic_or ;
+	logic_or       → logic_and ( "or" logic_and )* ;
+	logic_and      → equality ( "and" equality )* ;
 	equality       → comparison ( ( "!=" | "==" ) comparison )* ;
 	comparison     → term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
 	term           → factor ( ( "-" | "+" ) factor )* ;
 	factor         → unary ( ( "/" | "*" ) unary )* ;
 	unary          → ( "!" | "-" ) unary
-				| primary ;
-	primary        → NUMBER | STRING | "true" | "false" | "nil"
-				| "(" expression ")" ;
+				| call ;
+	call           → primary ( "(" arguments? ")" | "." IDENTIFIER
+				| "[" expression "]" | "[" expression? ":" expression? "]" )* ;
+	arguments      → expression ( "," expression )* ;
+	primary        → "true" | "false" | "nil" | "this"
+				| NUMBER | STRING | IDENTIFIER | "(" expression ")"
+				| "super" "." IDENTIFIER
+				| "[" ( expression ( "," expression )* ","? )? "]"
+				| "{" ( entry ( "," entry )* ","? )? "}" ;
+	entry          → expression ":" expression ;
+
+A "{" starting a statement always opens a block; map literals can only
+appear where an expression is expected.
 
 */
 
+// maxArgs caps the number of arguments of a call and parameters of a function.
+const maxArgs = 255
+
+// maxDepth caps the nesting of statements and expressions, so that deeply
+// nested input is reported instead of overflowing the Go stack, here or in
+// the passes walking the tree afterwards.
+const maxDepth = 256
+
 type Parser struct {
 	Tokens  []token.Token
 	current int64
+	// depth is the nesting level of the node being parsed, see nest.
+	depth    int
+	errs     []error
+	reporter errors.Reporter
 }
 
-func (p *Parser) Parse() ([]ast.Stmt, error) {
-	stmts := make([]ast.Stmt, 0)
+// bailout is panicked to abandon parsing and recovered by Parse.
+type bailout struct{}
+
+func New(tokens []token.Token, reporter errors.Reporter) *Parser {
+	return &Parser{
+		Tokens:   tokens,
+		reporter: reporter,
+	}
+}
+
+// Parse parses the whole token stream. A syntax error does not stop parsing:
+// the parser resynchronizes at the next statement boundary, so every error in
+// the source is reported. Statements containing errors are left out of the
+// result, and the returned error joins all reported errors.
+func (p *Parser) Parse() (stmts []ast.Stmt, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			err = stderrors.Join(p.errs...)
+		}
+	}()
+
+	stmts = make([]ast.Stmt, 0)
 	for !p.isAtEnd() {
-		stmts = append(stmts, p.statment())
+		if stmt := p.declaration(); stmt != nil {
+			stmts = append(stmts, stmt)
+		}
 	}
-	return stmts, nil
+	return stmts, stderrors.Join(p.errs...)
+}
+
+// declaration returns nil if the declaration has a syntax error, after
+// skipping to the start of the next statement.
+func (p *Parser) declaration() ast.Stmt {
+	var stmt ast.Stmt
+	var err error
+	if p.match(token.CLASS) {
+		stmt, err = p.classDeclaration()
+	} else if p.match(token.FUN) {
+		stmt, err = p.function("function")
+	} else if p.match(token.VAR) {
+		stmt, err = p.varDeclaration()
+	} else {
+		stmt, err = p.statment()
+	}
+
+	if err != nil {
+		p.synchronize()
+		return nil
+	}
+	return stmt
+}
+
+func (p *Parser) classDeclaration() (ast.Stmt, error) {
+	name, err := p.consume(token.IDENTIFIER, "expect class name.")
+	if err != nil {
+		return nil, err
+	}
+
+	var superclass *ast.Variable
+	if p.match(token.LESS) {
+		superName, err := p.consume(token.IDENTIFIER, "expect superclass name.")
+		if err != nil {
+			return nil, err
+		}
+		superclass = &ast.Variable{Name: superName}
+	}
+
+	if _, err := p.consume(token.LEFT_BRACE, "expect '{' before class body."); err != nil {
+		return nil, err
+	}
+
+	methods := make([]*ast.Function, 0)
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		method, err := p.function("method")
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+	}
+
+	if _, err := p.consume(token.RIGHT_BRACE, "expect '}' after class body."); err != nil {
+		return nil, err
+	}
+	return &ast.Class{Name: name, Superclass: superclass, Methods: methods}, nil
+}
+
+// function parses the name, parameters and body of a function. kind is used
+// in error messages only.
+func (p *Parser) function(kind string) (*ast.Function, error) {
+	defer p.restoreDepth(p.depth)
+	p.nest()
+	name, err := p.consume(token.IDENTIFIER, "expect "+kind+" name.")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LEFT_PAREN, "expect '(' after "+kind+" name."); err != nil {
+		return nil, err
+	}
+
+	params := make([]token.Token, 0)
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			if len(params) >= maxArgs {
+				// Report but keep parsing: the parser is not in a confused state.
+				p.error(p.peek(), "can't have more than 255 parameters.")
+			}
+			param, err := p.consume(token.IDENTIFIER, "expect parameter name.")
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, param)
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "expect ')' after parameters."); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(token.LEFT_BRACE, "expect '{' before "+kind+" body."); err != nil {
+		return nil, err
+	}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	return &ast.Function{Name: name, Params: params, Body: body}, nil
+}
+
+func (p *Parser) varDeclaration() (ast.Stmt, error) {
+	name, err := p.consume(token.IDENTIFIER, "expect variable name.")
+	if err != nil {
+		return nil, err
+	}
+
+	var initializer ast.Expr
+	if p.match(token.EQUAL) {
+		initializer, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.consume(token.SEMICOLON, "expect ';' after variable declaration."); err != nil {
+		return nil, err
+	}
+	return &ast.Var{Name: name, Initializer: initializer}, nil
 }
 
-func (p *Parser) statment() ast.Stmt {
+func (p *Parser) statment() (ast.Stmt, error) {
+	defer p.restoreDepth(p.depth)
+	p.nest()
+	if p.match(token.FOR) {
+		return p.forStatement()
+	}
+	if p.match(token.IF) {
+		return p.ifStatement()
+	}
 	if p.match(token.PRINT) {
 		return p.printStatement()
 	}
+	if p.match(token.RETURN) {
+		return p.returnStatement()
+	}
+	if p.match(token.WHILE) {
+		return p.whileStatement()
+	}
+	if p.match(token.LEFT_BRACE) {
+		stmts, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Block{Statements: stmts}, nil
+	}
 	return p.expressionStatement()
 }
 
-func (p *Parser) printStatement() ast.Stmt {
-	value, _ := p.expression()
-	p.consume(token.SEMICOLON, "expect ';' after expression.")
-	return &ast.Print{Expression: value}
+// block parses the declarations up to the closing brace. An erroneous
+// declaration inside the block is dropped and parsing resumes with the next
+// one, so only a missing '}' fails the block itself.
+func (p *Parser) block() ([]ast.Stmt, error) {
+	stmts := make([]ast.Stmt, 0)
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		if stmt := p.declaration(); stmt != nil {
+			stmts = append(stmts, stmt)
+		}
+	}
+
+	if _, err := p.consume(token.RIGHT_BRACE, "expect '}' after block."); err != nil {
+		return nil, err
+	}
+	return stmts, nil
+}
+
+// forStatement has no node of its own: it is desugared into an equivalent
+// block wrapping a while loop.
+func (p *Parser) forStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.LEFT_PAREN, "expect '(' after 'for'."); err != nil {
+		return nil, err
+	}
+
+	var initializer ast.Stmt
+	var err error
+	if p.match(token.SEMICOLON) {
+		initializer = nil
+	} else if p.match(token.VAR) {
+		initializer, err = p.varDeclaration()
+	} else {
+		initializer, err = p.expressionStatement()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var condition ast.Expr
+	if !p.check(token.SEMICOLON) {
+		condition, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.consume(token.SEMICOLON, "expect ';' after loop condition."); err != nil {
+		return nil, err
+	}
+
+	var increment ast.Expr
+	if !p.check(token.RIGHT_PAREN) {
+		increment, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "expect ')' after for clauses."); err != nil {
+		return nil, err
+	}
+
+	body, err := p.statment()
+	if err != nil {
+		return nil, err
+	}
+
+	if increment != nil {
+		body = &ast.Block{Statements: []ast.Stmt{body, &ast.Expression{Expression: increment}}}
+	}
+	if condition == nil {
+		condition = &ast.Literal{Value: true}
+	}
+	body = &ast.While{Keyword: keyword, Condition: condition, Body: body}
+	if initializer != nil {
+		body = &ast.Block{Statements: []ast.Stmt{initializer, body}}
+	}
+
+	return body, nil
+}
+
+func (p *Parser) ifStatement() (ast.Stmt, error) {
+	if _, err := p.consume(token.LEFT_PAREN, "expect '(' after 'if'."); err != nil {
+		return nil, err
+	}
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "expect ')' after if condition."); err != nil {
+		return nil, err
+	}
+
+	thenBranch, err := p.statment()
+	if err != nil {
+		return nil, err
+	}
+	var elseBranch ast.Stmt
+	if p.match(token.ELSE) {
+		elseBranch, err = p.statment()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.If{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}, nil
 }
 
-func (p *Parser) expressionStatement() ast.Stmt {
-	value, _ := p.expression()
-	p.consume(token.SEMICOLON, "expect ';' after expression.")
-	return &ast.Expression{Expression: value}
+func (p *Parser) returnStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	var value ast.Expr
+	if !p.check(token.SEMICOLON) {
+		var err error
+		value, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.consume(token.SEMICOLON, "expect ';' after return value."); err != nil {
+		return nil, err
+	}
+	return &ast.Return{Keyword: keyword, Value: value}, nil
+}
+
+func (p *Parser) whileStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.LEFT_PAREN, "expect '(' after 'while'."); err != nil {
+		return nil, err
+	}
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "expect ')' after condition."); err != nil {
+		return nil, err
+	}
+	body, err := p.statment()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.While{Keyword: keyword, Condition: condition, Body: body}, nil
+}
+
+func (p *Parser) printStatement() (ast.Stmt, error) {
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.SEMICOLON, "expect ';' after expression."); err != nil {
+		return nil, err
+	}
+	return &ast.Print{Expression: value}, nil
+}
+
+func (p *Parser) expressionStatement() (ast.Stmt, error) {
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.SEMICOLON, "expect ';' after expression."); err != nil {
+		return nil, err
+	}
+	return &ast.Expression{Expression: value}, nil
 }
 
 func (p *Parser) expression() (ast.Expr, error) {
-	return p.equality()
+	defer p.restoreDepth(p.depth)
+	p.nest()
+	return p.assignment()
+}
+
+func (p *Parser) assignment() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(token.EQUAL) {
+		equals := p.previous()
+		p.nest()
+		value, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+
+		switch target := expr.(type) {
+		case *ast.Variable:
+			return &ast.Assign{Name: target.Name, Value: value}, nil
+		case *ast.Get:
+			return &ast.Set{Object: target.Object, Name: target.Name, Value: value}, nil
+		case *ast.Index:
+			return &ast.IndexSet{Object: target.Object, Bracket: target.Bracket, Index: target.Index, Value: value}, nil
+		}
+
+		// Report but don't bail out: the parser is not in a confused state.
+		p.error(equals, "invalid assignment target.")
+	}
+
+	return expr, nil
+}
+
+func (p *Parser) or() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
+	expr, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(token.OR) {
+		p.nest()
+		operator := p.previous()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		expr = &ast.Logical{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
+}
+
+func (p *Parser) and() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
+	expr, err := p.equality()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(token.AND) {
+		p.nest()
+		operator := p.previous()
+		right, err := p.equality()
+		if err != nil {
+			return nil, err
+		}
+		expr = &ast.Logical{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr, nil
 }
 
 func (p *Parser) equality() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
 	expr, err := p.comparison()
 	if err != nil {
 		return nil, err
 	}
 	for p.match(token.BANG_EQUAL, token.EQUAL_EQUAL) {
+		p.nest()
 		operator := p.previous()
 		right, err := p.comparison()
 		if err != nil {
@@ -78,11 +528,13 @@ func (p *Parser) equality() (ast.Expr, error) {
 }
 
 func (p *Parser) comparison() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
 	expr, err := p.term()
 	if err != nil {
 		return nil, err
 	}
 	for p.match(token.LESS_EQUAL, token.LESS, token.GREATER, token.GREATER_EQUAL) {
+		p.nest()
 		operator := p.previous()
 		right, err := p.term()
 		if err != nil {
@@ -95,11 +547,13 @@ func (p *Parser) comparison() (ast.Expr, error) {
 }
 
 func (p *Parser) term() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
 	expr, err := p.factor()
 	if err != nil {
 		return nil, err
 	}
 	for p.match(token.PLUS, token.MINUS) {
+		p.nest()
 		operator := p.previous()
 		right, err := p.factor()
 		if err != nil {
@@ -112,12 +566,14 @@ func (p *Parser) term() (ast.Expr, error) {
 }
 
 func (p *Parser) factor() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
 	expr, err := p.unary()
 	if err != nil {
 		return nil, err
 	}
 
 	for p.match(token.STAR, token.SLASH) {
+		p.nest()
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -131,6 +587,8 @@ func (p *Parser) factor() (ast.Expr, error) {
 
 func (p *Parser) unary() (ast.Expr, error) {
 	if p.match(token.BANG, token.MINUS) {
+		defer p.restoreDepth(p.depth)
+		p.nest()
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -139,25 +597,148 @@ func (p *Parser) unary() (ast.Expr, error) {
 		return &ast.Unary{Operator: operator, Right: right}, nil
 	}
 
-	return p.primary()
+	return p.call()
+}
+
+func (p *Parser) call() (ast.Expr, error) {
+	defer p.restoreDepth(p.depth)
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.match(token.LEFT_PAREN) {
+			p.nest()
+			expr, err = p.finishCall(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(token.DOT) {
+			p.nest()
+			name, err := p.consume(token.IDENTIFIER, "expect property name after '.'.")
+			if err != nil {
+				return nil, err
+			}
+			expr = &ast.Get{Object: expr, Name: name}
+		} else if p.match(token.LEFT_BRACKET) {
+			p.nest()
+			expr, err = p.finishIndex(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			break
+		}
+	}
+
+	return expr, nil
+}
+
+// finishIndex parses the rest of object[index] or object[start:end].
+func (p *Parser) finishIndex(object ast.Expr) (ast.Expr, error) {
+	bracket := p.previous()
+	var index ast.Expr
+	if !p.check(token.COLON) {
+		var err error
+		index, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.match(token.COLON) {
+		var end ast.Expr
+		if !p.check(token.RIGHT_BRACKET) {
+			var err error
+			end, err = p.expression()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.consume(token.RIGHT_BRACKET, "expect ']' after slice."); err != nil {
+			return nil, err
+		}
+		return &ast.Slice{Object: object, Bracket: bracket, Start: index, End: end}, nil
+	}
+
+	if _, err := p.consume(token.RIGHT_BRACKET, "expect ']' after index."); err != nil {
+		return nil, err
+	}
+	return &ast.Index{Object: object, Bracket: bracket, Index: index}, nil
+}
+
+func (p *Parser) finishCall(callee ast.Expr) (ast.Expr, error) {
+	args := make([]ast.Expr, 0)
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			if len(args) >= maxArgs {
+				// Report but keep parsing: the parser is not in a confused state.
+				p.error(p.peek(), "can't have more than 255 arguments.")
+			}
+			arg, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+
+	paren, err := p.consume(token.RIGHT_PAREN, "expect ')' after arguments.")
+	if err != nil {
+		return nil, err
+	}
+	return &ast.Call{Callee: callee, Paren: paren, Arguments: args}, nil
 }
 
 func (p *Parser) primary() (ast.Expr, error) {
 	if p.match(token.FALSE) {
-		return &ast.Literal{Value: false}, nil
+		return &ast.Literal{Value: false, Token: p.previous()}, nil
 	}
 	if p.match(token.TRUE) {
-		return &ast.Literal{Value: true}, nil
+		return &ast.Literal{Value: true, Token: p.previous()}, nil
 	}
 	if p.match(token.NIL) {
-		return &ast.Literal{Value: nil}, nil
+		return &ast.Literal{Value: nil, Token: p.previous()}, nil
 	}
 	if p.match(token.NUMBER, token.STRING) {
-		return &ast.Literal{Value: p.previous().Literal}, nil
+		return &ast.Literal{Value: p.previous().Literal, Token: p.previous()}, nil
+	}
+
+	if p.match(token.SUPER) {
+		keyword := p.previous()
+		_, err := p.consume(token.DOT, "expect '.' after 'super'.")
+		if err != nil {
+			return nil, err
+		}
+		method, err := p.consume(token.IDENTIFIER, "expect superclass method name.")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Super{Keyword: keyword, Method: method}, nil
+	}
+	if p.match(token.THIS) {
+		return &ast.This{Keyword: p.previous()}, nil
+	}
+	if p.match(token.IDENTIFIER) {
+		return &ast.Variable{Name: p.previous()}, nil
+	}
+
+	if p.match(token.LEFT_BRACKET) {
+		return p.listLiteral()
+	}
+	if p.match(token.LEFT_BRACE) {
+		return p.mapLiteral()
 	}
 
 	if p.match(token.LEFT_PAREN) {
 		expr, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
 		_, err = p.consume(token.RIGHT_PAREN, "expected ')' after expression.")
 		if err != nil {
 			return nil, err
@@ -165,7 +746,54 @@ func (p *Parser) primary() (ast.Expr, error) {
 		return &ast.Grouping{Expression: expr}, nil
 	}
 
-	return nil, errors.ReportParseError(p.peek(), "expression expected")
+	return nil, p.error(p.peek(), "expression expected")
+}
+
+func (p *Parser) listLiteral() (ast.Expr, error) {
+	bracket := p.previous()
+	elements := make([]ast.Expr, 0)
+	for !p.check(token.RIGHT_BRACKET) {
+		element, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+	if _, err := p.consume(token.RIGHT_BRACKET, "expect ']' after list elements."); err != nil {
+		return nil, err
+	}
+	return &ast.ListLiteral{Bracket: bracket, Elements: elements}, nil
+}
+
+func (p *Parser) mapLiteral() (ast.Expr, error) {
+	brace := p.previous()
+	keys := make([]ast.Expr, 0)
+	values := make([]ast.Expr, 0)
+	for !p.check(token.RIGHT_BRACE) {
+		key, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.consume(token.COLON, "expect ':' after map key."); err != nil {
+			return nil, err
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+	if _, err := p.consume(token.RIGHT_BRACE, "expect '}' after map entries."); err != nil {
+		return nil, err
+	}
+	return &ast.MapLiteral{Brace: brace, Keys: keys, Values: values}, nil
 }
 
 func (p *Parser) consume(tokenType token.TokenType, errorString string) (token.Token, error) {
@@ -173,10 +801,36 @@ func (p *Parser) consume(tokenType token.TokenType, errorString string) (token.T
 		return p.advance(), nil
 	}
 
-	err := errors.ReportParseError(p.Tokens[p.current], errorString)
+	err := p.error(p.peek(), errorString)
 	return *new(token.Token), err
 }
 
+// nest enters one more level of nesting: a nested statement or expression,
+// or another operator or postfix applied to the expression being built,
+// which deepens the tree just as much. Past maxDepth it reports an error and
+// abandons parsing, as unwinding normally would report a missing ')' or '}'
+// for every enclosing level.
+func (p *Parser) nest() {
+	p.depth++
+	if p.depth > maxDepth {
+		p.error(p.peek(), "nesting too deep.")
+		panic(bailout{})
+	}
+}
+
+// restoreDepth leaves the levels entered since depth was current.
+func (p *Parser) restoreDepth(depth int) {
+	p.depth = depth
+}
+
+// error reports a syntax error at t and records it for Parse's result.
+func (p *Parser) error(t token.Token, message string) error {
+	p.reporter.Report(errors.AtToken(t, errors.CodeParse, message))
+	err := errors.ParseErr{Line: t.Line, Message: message}
+	p.errs = append(p.errs, err)
+	return err
+}
+
 func (p *Parser) match(types ...token.TokenType) bool {
 	if slices.ContainsFunc(types, p.check) {
 		p.advance()
@@ -221,14 +875,8 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().TokenType {
-		case token.CLASS:
-		case token.FUN:
-		case token.VAR:
-		case token.FOR:
-		case token.IF:
-		case token.WHILE:
-		case token.PRINT:
-		case token.RETURN:
+		case token.CLASS, token.FUN, token.VAR, token.FOR,
+			token.IF, token.WHILE, token.PRINT, token.RETURN:
 			return
 		}
 
@@ -0,0 +1,379 @@
+package resolver
+
+import (
+	"github.com/anwprath/glox/ast"
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/token"
+)
+
+var _ ast.ExprVisitor = &Resolver{}
+var _ ast.StmtVisitor = &Resolver{}
+
+type functionType int
+
+const (
+	functionNone functionType = iota
+	functionFunction
+	functionInitializer
+	functionMethod
+)
+
+type classType int
+
+const (
+	classNone classType = iota
+	classClass
+	classSubclass
+)
+
+// Locals receives the binding depth of every resolved local variable. The
+// tree-walking interpreter implements it. References in top-level code,
+// outside any function, go to ResolveTopLevel, as that code runs only once.
+type Locals interface {
+	Resolve(expr ast.Expr, depth int)
+	ResolveTopLevel(expr ast.Expr, depth int)
+}
+
+// Resolver is a static pass run between parsing and interpreting. It binds
+// every local variable reference to the scope that declares it and reports
+// scope errors before any code executes.
+type Resolver struct {
+	locals Locals
+	// scopes is a stack of local scopes. A name maps to false while its
+	// initializer is being resolved and to true once it is ready for use.
+	// The global scope is never on the stack.
+	scopes          []map[string]bool
+	currentFunction functionType
+	currentClass    classType
+	hadError        bool
+	reporter        errors.Reporter
+}
+
+// New returns a resolver recording bindings into locals, which may be nil
+// when only the static checks are wanted.
+func New(locals Locals, reporter errors.Reporter) *Resolver {
+	return &Resolver{
+		locals:          locals,
+		reporter:        reporter,
+		scopes:          make([]map[string]bool, 0),
+		currentFunction: functionNone,
+		currentClass:    classNone,
+	}
+}
+
+// Resolve walks stmts and reports every static error found. The returned
+// error is non-nil if at least one was reported.
+func (r *Resolver) Resolve(stmts []ast.Stmt) error {
+	r.resolveStmts(stmts)
+	if r.hadError {
+		return errors.ResolveErr{}
+	}
+	return nil
+}
+
+func (r *Resolver) VisitBlockStmt(stmt *ast.Block) (any, error) {
+	r.beginScope()
+	r.resolveStmts(stmt.Statements)
+	r.endScope()
+	return nil, nil
+}
+
+func (r *Resolver) VisitClassStmt(stmt *ast.Class) (any, error) {
+	enclosingClass := r.currentClass
+	r.currentClass = classClass
+	defer func() { r.currentClass = enclosingClass }()
+
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+
+	if stmt.Superclass != nil {
+		if stmt.Superclass.Name.Lexeme == stmt.Name.Lexeme {
+			r.error(stmt.Superclass.Name, "a class can't inherit from itself.")
+		}
+
+		r.currentClass = classSubclass
+		r.resolveExpr(stmt.Superclass)
+
+		r.beginScope()
+		r.peekScope()["super"] = true
+	}
+
+	r.beginScope()
+	r.peekScope()["this"] = true
+
+	for _, method := range stmt.Methods {
+		declaration := functionMethod
+		if method.Name.Lexeme == "init" {
+			declaration = functionInitializer
+		}
+		r.resolveFunction(method, declaration)
+	}
+
+	r.endScope()
+
+	if stmt.Superclass != nil {
+		r.endScope()
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitExpressionStmt(stmt *ast.Expression) (any, error) {
+	r.resolveExpr(stmt.Expression)
+	return nil, nil
+}
+
+func (r *Resolver) VisitFunctionStmt(stmt *ast.Function) (any, error) {
+	r.declare(stmt.Name)
+	// Defined eagerly so the function can refer to itself recursively.
+	r.define(stmt.Name)
+
+	r.resolveFunction(stmt, functionFunction)
+	return nil, nil
+}
+
+func (r *Resolver) VisitIfStmt(stmt *ast.If) (any, error) {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		r.resolveStmt(stmt.ElseBranch)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitPrintStmt(stmt *ast.Print) (any, error) {
+	r.resolveExpr(stmt.Expression)
+	return nil, nil
+}
+
+func (r *Resolver) VisitReturnStmt(stmt *ast.Return) (any, error) {
+	if r.currentFunction == functionNone {
+		r.error(stmt.Keyword, "can't return from top-level code.")
+	}
+
+	if stmt.Value != nil {
+		if r.currentFunction == functionInitializer {
+			r.error(stmt.Keyword, "can't return a value from an initializer.")
+		}
+		r.resolveExpr(stmt.Value)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitVarStmt(stmt *ast.Var) (any, error) {
+	r.declare(stmt.Name)
+	if stmt.Initializer != nil {
+		r.resolveExpr(stmt.Initializer)
+	}
+	r.define(stmt.Name)
+	return nil, nil
+}
+
+func (r *Resolver) VisitWhileStmt(stmt *ast.While) (any, error) {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.Body)
+	return nil, nil
+}
+
+func (r *Resolver) VisitAssignExpr(expr *ast.Assign) (any, error) {
+	r.resolveExpr(expr.Value)
+	r.resolveLocal(expr, expr.Name)
+	return nil, nil
+}
+
+func (r *Resolver) VisitBinaryExpr(expr *ast.Binary) (any, error) {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil, nil
+}
+
+func (r *Resolver) VisitCallExpr(expr *ast.Call) (any, error) {
+	r.resolveExpr(expr.Callee)
+	for _, arg := range expr.Arguments {
+		r.resolveExpr(arg)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitGetExpr(expr *ast.Get) (any, error) {
+	r.resolveExpr(expr.Object)
+	return nil, nil
+}
+
+func (r *Resolver) VisitGroupingExpr(expr *ast.Grouping) (any, error) {
+	r.resolveExpr(expr.Expression)
+	return nil, nil
+}
+
+func (r *Resolver) VisitIndexExpr(expr *ast.Index) (any, error) {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	return nil, nil
+}
+
+func (r *Resolver) VisitIndexSetExpr(expr *ast.IndexSet) (any, error) {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	return nil, nil
+}
+
+func (r *Resolver) VisitListLiteralExpr(expr *ast.ListLiteral) (any, error) {
+	for _, element := range expr.Elements {
+		r.resolveExpr(element)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitLiteralExpr(expr *ast.Literal) (any, error) {
+	return nil, nil
+}
+
+func (r *Resolver) VisitLogicalExpr(expr *ast.Logical) (any, error) {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil, nil
+}
+
+func (r *Resolver) VisitMapLiteralExpr(expr *ast.MapLiteral) (any, error) {
+	for i, key := range expr.Keys {
+		r.resolveExpr(key)
+		r.resolveExpr(expr.Values[i])
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitSetExpr(expr *ast.Set) (any, error) {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	return nil, nil
+}
+
+func (r *Resolver) VisitSliceExpr(expr *ast.Slice) (any, error) {
+	r.resolveExpr(expr.Object)
+	if expr.Start != nil {
+		r.resolveExpr(expr.Start)
+	}
+	if expr.End != nil {
+		r.resolveExpr(expr.End)
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitSuperExpr(expr *ast.Super) (any, error) {
+	switch r.currentClass {
+	case classNone:
+		r.error(expr.Keyword, "can't use 'super' outside of a class.")
+	case classClass:
+		r.error(expr.Keyword, "can't use 'super' in a class with no superclass.")
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitThisExpr(expr *ast.This) (any, error) {
+	if r.currentClass == classNone {
+		r.error(expr.Keyword, "can't use 'this' outside of a class.")
+		return nil, nil
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitUnaryExpr(expr *ast.Unary) (any, error) {
+	r.resolveExpr(expr.Right)
+	return nil, nil
+}
+
+func (r *Resolver) VisitVariableExpr(expr *ast.Variable) (any, error) {
+	if len(r.scopes) > 0 {
+		if ready, ok := r.peekScope()[expr.Name.Lexeme]; ok && !ready {
+			r.error(expr.Name, "can't read local variable in its own initializer.")
+		}
+	}
+
+	r.resolveLocal(expr, expr.Name)
+	return nil, nil
+}
+
+func (r *Resolver) resolveStmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		r.resolveStmt(stmt)
+	}
+}
+
+func (r *Resolver) resolveStmt(stmt ast.Stmt) {
+	stmt.Accept(r)
+}
+
+func (r *Resolver) resolveExpr(expr ast.Expr) {
+	expr.Accept(r)
+}
+
+func (r *Resolver) resolveFunction(function *ast.Function, kind functionType) {
+	enclosingFunction := r.currentFunction
+	r.currentFunction = kind
+	defer func() { r.currentFunction = enclosingFunction }()
+
+	r.beginScope()
+	for _, param := range function.Params {
+		r.declare(param)
+		r.define(param)
+	}
+	r.resolveStmts(function.Body)
+	r.endScope()
+}
+
+func (r *Resolver) resolveLocal(expr ast.Expr, name token.Token) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name.Lexeme]; ok {
+			depth := len(r.scopes) - 1 - i
+			switch {
+			case r.locals == nil:
+			case r.currentFunction == functionNone:
+				r.locals.ResolveTopLevel(expr, depth)
+			default:
+				r.locals.Resolve(expr, depth)
+			}
+			return
+		}
+	}
+	// Not found: assume it is global.
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool))
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *Resolver) peekScope() map[string]bool {
+	return r.scopes[len(r.scopes)-1]
+}
+
+func (r *Resolver) declare(name token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	scope := r.peekScope()
+	if _, ok := scope[name.Lexeme]; ok {
+		r.error(name, "already a variable with this name in this scope.")
+	}
+	scope[name.Lexeme] = false
+}
+
+func (r *Resolver) define(name token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	r.peekScope()[name.Lexeme] = true
+}
+
+func (r *Resolver) error(t token.Token, message string) {
+	r.reporter.Report(errors.AtToken(t, errors.CodeResolve, message))
+	r.hadError = true
+}
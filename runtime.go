@@ -0,0 +1,246 @@
+// Package glox embeds the Lox language in Go programs.
+//
+//	rt := glox.NewRuntime(glox.Options{Stdout: &buf})
+//	rt.SetGlobal("limit", 10)
+//	if err := rt.Eval(ctx, `print limit * 2;`); err != nil { ... }
+package glox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/anwprath/glox/ast"
+	"github.com/anwprath/glox/compiler"
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/interpreter"
+	"github.com/anwprath/glox/native"
+	"github.com/anwprath/glox/optimizer"
+	"github.com/anwprath/glox/parser"
+	"github.com/anwprath/glox/resolver"
+	"github.com/anwprath/glox/scanner"
+	"github.com/anwprath/glox/source"
+	"github.com/anwprath/glox/stdlib"
+	"github.com/anwprath/glox/vm"
+)
+
+// Errors matched with errors.Is by a failed Eval, re-exported so that
+// embedders need not import the errors package.
+var (
+	ErrCanceled       = errors.ErrCanceled
+	ErrBudgetExceeded = errors.ErrBudgetExceeded
+	ErrMemoryLimit    = errors.ErrMemoryLimit
+)
+
+type Backend int
+
+const (
+	// BackendTree runs scripts with the tree-walking interpreter.
+	BackendTree Backend = iota
+	// BackendVM compiles scripts to bytecode and runs them on the VM.
+	BackendVM
+)
+
+type Options struct {
+	// Stdout receives the output of print statements. Defaults to os.Stdout.
+	Stdout io.Writer
+	// Reporter additionally receives every diagnostic as it is produced.
+	// Eval returns the diagnostics of a failed run either way.
+	Reporter errors.Reporter
+	Backend  Backend
+	// Optimize enables constant folding and dead branch elimination.
+	Optimize bool
+	// StepBudget limits each Eval to that many function calls and loop
+	// iterations. Zero means no limit.
+	StepBudget int
+	// MaxCallDepth bounds how deeply Lox calls may nest before Eval fails
+	// with "Stack overflow.". Zero means object.DefaultMaxCallDepth.
+	MaxCallDepth int
+	// MemoryLimit limits the approximate number of bytes of strings,
+	// objects and functions each Eval may allocate. Zero means no limit.
+	MemoryLimit int
+	// Capabilities selects the built-ins that reach outside the script.
+	// The zero value is a sandbox; use stdlib.Full() for trusted scripts.
+	Capabilities stdlib.Capabilities
+}
+
+// engine is what the runtime needs from a backend.
+type engine interface {
+	SetOutput(w io.Writer)
+	SetStepBudget(n int)
+	SetMaxCallDepth(n int)
+	SetMemoryLimit(bytes int)
+	DefineGlobal(name string, value any)
+	GetGlobal(name string) (any, bool)
+	DefineNative(name string, arity int, fn func(args []any) (any, error))
+	DefineFunction(function *native.Function)
+	DefineFunc(name string, fn any) error
+}
+
+// Runtime is an isolated Lox environment. Globals persist between calls to
+// Eval. A Runtime must not be used from several goroutines at once, but
+// separate Runtimes are independent of each other.
+type Runtime struct {
+	opts        Options
+	reporter    *forwardingReporter
+	interpreter *interpreter.Interpreter
+	vm          *vm.VM
+	engine      engine
+}
+
+func NewRuntime(opts Options) *Runtime {
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+
+	rt := &Runtime{
+		opts:     opts,
+		reporter: &forwardingReporter{next: opts.Reporter},
+	}
+	switch opts.Backend {
+	case BackendVM:
+		rt.vm = vm.New(rt.reporter)
+		rt.engine = rt.vm
+	default:
+		rt.interpreter = interpreter.New(rt.reporter)
+		rt.engine = rt.interpreter
+	}
+	rt.engine.SetOutput(opts.Stdout)
+	rt.engine.SetStepBudget(opts.StepBudget)
+	rt.engine.SetMaxCallDepth(opts.MaxCallDepth)
+	rt.engine.SetMemoryLimit(opts.MemoryLimit)
+	stdlib.Install(rt.engine, opts.Capabilities)
+	return rt
+}
+
+// Eval runs src. It returns an *Error if src fails to compile or raises a
+// runtime error; statements before a runtime error keep their effects.
+// Running scripts are aborted once ctx is done or the step budget is used
+// up; the error then matches ErrCanceled or ErrBudgetExceeded with
+// errors.Is. Exceeding the memory limit matches ErrMemoryLimit.
+func (rt *Runtime) Eval(ctx context.Context, src string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", errors.ErrCanceled, err)
+	}
+
+	rt.reporter.Diagnostics = nil
+	stmts, err := rt.parse(src)
+	if err != nil {
+		return rt.fail(err)
+	}
+
+	if rt.vm != nil {
+		function, err := compiler.New(rt.reporter).Compile(stmts)
+		if err != nil {
+			return rt.fail(err)
+		}
+		if err := rt.vm.InterpretContext(ctx, function); err != nil {
+			return rt.fail(err)
+		}
+		return nil
+	}
+
+	if err := rt.interpreter.InterpretContext(ctx, stmts); err != nil {
+		return rt.fail(err)
+	}
+	return nil
+}
+
+func (rt *Runtime) parse(src string) ([]ast.Stmt, error) {
+	// Diagnostics only carry line numbers, so nothing needs the source once
+	// Eval returns: a FileSet per call lets it be collected.
+	sc := scanner.New(source.NewFileSet(), "<eval>", src, rt.reporter)
+	tokens := sc.ScanTokens()
+	stmts, err := parser.New(tokens, rt.reporter).Parse()
+	if err != nil {
+		return nil, err
+	}
+	if rt.reporter.HasErrors() {
+		return nil, errors.ScanErr{}
+	}
+
+	var locals resolver.Locals
+	if rt.interpreter != nil {
+		locals = rt.interpreter
+	}
+	if err := resolver.New(locals, rt.reporter).Resolve(stmts); err != nil {
+		return nil, err
+	}
+
+	if rt.opts.Optimize {
+		stmts = optimizer.New().Optimize(stmts)
+	}
+	return stmts, nil
+}
+
+func (rt *Runtime) fail(cause error) error {
+	return &Error{Diagnostics: rt.reporter.Diagnostics, cause: cause}
+}
+
+// SetGlobal defines a global variable visible to later scripts. Go numbers
+// become Lox numbers; nil, bools and strings map to themselves. Go functions
+// become native Lox functions named name, see native.Wrap, and pointers to
+// structs become objects with properties and methods, see native.Reflect.
+func (rt *Runtime) SetGlobal(name string, value any) error {
+	if value != nil && reflect.TypeOf(value).Kind() == reflect.Func {
+		return rt.engine.DefineFunc(name, value)
+	}
+
+	loxValue, err := native.ToLox(value)
+	if err != nil {
+		return err
+	}
+	rt.engine.DefineGlobal(name, loxValue)
+	return nil
+}
+
+// GetGlobal returns the current value of a global variable as a Go value:
+// nil, bool, float64, string, or a runtime object.
+func (rt *Runtime) GetGlobal(name string) (any, bool) {
+	return rt.engine.GetGlobal(name)
+}
+
+// DefineNative makes fn callable from Lox as the global function name. An
+// error returned by fn is raised as a Lox runtime error at the call site.
+func (rt *Runtime) DefineNative(name string, arity int, fn func(args []any) (any, error)) {
+	rt.engine.DefineNative(name, arity, fn)
+}
+
+// Error describes a failed Eval.
+type Error struct {
+	Diagnostics []errors.Diagnostic
+	cause       error
+}
+
+func (e *Error) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return e.cause.Error()
+	}
+	lines := make([]string, 0, len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the underlying error, e.g. an errors.RuntimeError.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// forwardingReporter collects the diagnostics of the current Eval and passes
+// them on to the user's Reporter, if any.
+type forwardingReporter struct {
+	errors.Collector
+	next errors.Reporter
+}
+
+func (r *forwardingReporter) Report(d errors.Diagnostic) {
+	r.Collector.Report(d)
+	if r.next != nil {
+		r.next.Report(d)
+	}
+}
@@ -0,0 +1,223 @@
+package glox
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var backends = map[string]Backend{"tree": BackendTree, "vm": BackendVM}
+
+func TestMemoryLimit(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		src  string
+	}{
+		{"push", `var l = []; for (var i = 0; i < 100000; i = i + 1) l.push(i);`},
+		{"insert", `var l = []; for (var i = 0; i < 100000; i = i + 1) l.insert(0, i);`},
+		{"new keys", `var m = {}; for (var i = 0; i < 100000; i = i + 1) m[i] = i;`},
+		{"keys", `var m = {1: 1}; for (var i = 0; i < 100000; i = i + 1) m.keys();`},
+		{"replace", `var s = "a"; for (var i = 0; i < 100; i = i + 1) s = replace(s, "a", "aa");`},
+		{"join", `var s = "a"; for (var i = 0; i < 100; i = i + 1) s = join([s, s], "");`},
+		{"split", `for (var i = 0; i < 100000; i = i + 1) split("abc", "");`},
+		{"upper", `for (var i = 0; i < 100000; i = i + 1) upper("abc");`},
+	} {
+		for name, backend := range backends {
+			rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend, MemoryLimit: 1000})
+			err := rt.Eval(context.Background(), test.src)
+			if !stderrors.Is(err, ErrMemoryLimit) {
+				t.Errorf("%s/%s: got %v, want the memory limit to be exceeded", test.name, name, err)
+			}
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		src  string
+	}{
+		{"loop", `cancel(); while (true) {}`},
+		{"call", `fun f() {} cancel(); f();`},
+		{"recursion", `fun f() { cancel(); f(); } f();`},
+	} {
+		for name, backend := range backends {
+			ctx, cancel := context.WithCancel(context.Background())
+			rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend})
+			rt.DefineNative("cancel", 0, func(args []any) (any, error) {
+				cancel()
+				return nil, nil
+			})
+			err := rt.Eval(ctx, test.src)
+			if !stderrors.Is(err, ErrCanceled) || !stderrors.Is(err, context.Canceled) {
+				t.Errorf("%s/%s: got %v, want a cancellation", test.name, name, err)
+			}
+		}
+	}
+}
+
+func TestCanceledBeforeEval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, backend := range backends {
+		var out strings.Builder
+		rt := NewRuntime(Options{Stdout: &out, Backend: backend})
+		err := rt.Eval(ctx, `print "ran";`)
+		if !stderrors.Is(err, ErrCanceled) || out.Len() > 0 {
+			t.Errorf("%s: got %v and output %q, want a cancellation before running", name, err, out.String())
+		}
+	}
+}
+
+func TestStepBudget(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		src  string
+	}{
+		{"loop", `while (true) {}`},
+		{"for", `for (var i = 0; i < 1000; i = i + 1) {}`},
+		{"calls", `fun f() {} f(); f(); f(); f(); f(); f(); f(); f(); f(); f(); f();`},
+		{"recursion", `fun f(n) { if (n > 0) f(n - 1); } f(100);`},
+	} {
+		for name, backend := range backends {
+			rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend, StepBudget: 10})
+			err := rt.Eval(context.Background(), test.src)
+			if !stderrors.Is(err, ErrBudgetExceeded) {
+				t.Errorf("%s/%s: got %v, want the step budget to be exceeded", test.name, name, err)
+			}
+		}
+	}
+
+	// The budget applies to each Eval anew.
+	for name, backend := range backends {
+		rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend, StepBudget: 10})
+		for range 3 {
+			if err := rt.Eval(context.Background(), `for (var i = 0; i < 5; i = i + 1) {}`); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestMemoryLimitIgnoresExistingKeys(t *testing.T) {
+	src := `var m = {"k": 0}; for (var i = 0; i < 100000; i = i + 1) m["k"] = i;`
+	for name, backend := range backends {
+		rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend, MemoryLimit: 1000})
+		if err := rt.Eval(context.Background(), src); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestMemoryLimitIgnoresExistingFields(t *testing.T) {
+	src := `class C {} var c = C(); for (var i = 0; i < 10000; i = i + 1) c.x = i;`
+	for name, backend := range backends {
+		rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend, MemoryLimit: 10000})
+		if err := rt.Eval(context.Background(), src); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestRuntimeErrorString(t *testing.T) {
+	want := "[line 2] Error at '-': Right operand must be a number."
+	for name, backend := range backends {
+		rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend})
+		err := rt.Eval(context.Background(), "var x = 1;\nprint x - \"a\";")
+		if err == nil || err.Error() != want {
+			t.Errorf("%s: got %v, want %q", name, err, want)
+		}
+	}
+}
+
+type hostDB struct {
+	URL string
+}
+
+type hostConfig struct {
+	MaxRetries int
+	DB         *hostDB
+}
+
+func (c *hostConfig) Endpoint(path string) string {
+	return c.DB.URL + path
+}
+
+func TestHostObjects(t *testing.T) {
+	for name, backend := range backends {
+		var out strings.Builder
+		rt := NewRuntime(Options{Stdout: &out, Backend: backend})
+		cfg := &hostConfig{MaxRetries: 3, DB: &hostDB{URL: "db:"}}
+		if err := rt.SetGlobal("cfg", cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		src := `print cfg.db == cfg.db; cfg.maxRetries = cfg.maxRetries + 1; print cfg.endpoint("/x");`
+		if err := rt.Eval(context.Background(), src); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if want := "true\ndb:/x\n"; out.String() != want || cfg.MaxRetries != 4 {
+			t.Errorf("%s: printed %q with maxRetries %d, want %q and 4", name, out.String(), cfg.MaxRetries, want)
+		}
+
+		err := rt.Eval(context.Background(), `cfg.maxRetries = 100000000000000000000000;`)
+		if err == nil || !strings.Contains(err.Error(), "Property 'maxRetries' is out of range.") {
+			t.Errorf("%s: got %v, want an out of range error", name, err)
+		}
+		if cfg.MaxRetries != 4 {
+			t.Errorf("%s: maxRetries is %d after a failed assignment", name, cfg.MaxRetries)
+		}
+	}
+}
+
+func TestNestingLimit(t *testing.T) {
+	deep := []string{
+		"print " + strings.Repeat("(", 100000) + "1" + strings.Repeat(")", 100000) + ";",
+		"print " + strings.Repeat("-", 100000) + "1;",
+		"print " + strings.Repeat("1 + ", 100000) + "1;",
+		strings.Repeat("{", 100000) + strings.Repeat("}", 100000),
+		strings.Repeat("if (true) ", 100000) + "print 1;",
+		"var a; a" + strings.Repeat(" = a", 100000) + ";",
+	}
+	for name, backend := range backends {
+		for _, src := range deep {
+			rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend})
+			err := rt.Eval(context.Background(), src)
+			if err == nil || !strings.Contains(err.Error(), "nesting too deep.") {
+				t.Errorf("%s: %.20s...: got %v, want a nesting error", name, src, err)
+			}
+		}
+
+		var out strings.Builder
+		rt := NewRuntime(Options{Stdout: &out, Backend: backend})
+		src := "print " + strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100) + ";"
+		if err := rt.Eval(context.Background(), src); err != nil || out.String() != "1\n" {
+			t.Errorf("%s: 100 nested parentheses: got %q, %v", name, out.String(), err)
+		}
+	}
+}
+
+func TestNumberLiteralOutOfRange(t *testing.T) {
+	want := "[line 1] Error: Number literal out of range."
+	for name, backend := range backends {
+		rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend})
+		err := rt.Eval(context.Background(), "print "+strings.Repeat("9", 400)+";")
+		if err == nil || err.Error() != want {
+			t.Errorf("%s: got %v, want %q", name, err, want)
+		}
+	}
+}
+
+func TestOptimizerKeepsMemoryCharges(t *testing.T) {
+	src := `var s = "` + strings.Repeat("a", 600) + `" + "` + strings.Repeat("b", 600) + `";`
+	for name, backend := range backends {
+		for _, optimize := range []bool{false, true} {
+			rt := NewRuntime(Options{Stdout: io.Discard, Backend: backend, Optimize: optimize, MemoryLimit: 1000})
+			if err := rt.Eval(context.Background(), src); !stderrors.Is(err, ErrMemoryLimit) {
+				t.Errorf("%s, optimize %v: got %v, want the memory limit to be exceeded", name, optimize, err)
+			}
+		}
+	}
+}
@@ -1,35 +1,66 @@
 package scanner
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/source"
 	"github.com/anwprath/glox/token"
 )
 
 type Scanner struct {
+	file                 *source.File
 	source               []rune
 	tokens               []token.Token
 	start, current, line int
+	// offsets[i] is the byte offset of source[i]; the extra last entry is the
+	// length of the source in bytes.
+	offsets []int
+	// lineStart is the index of the first rune of the current line, and
+	// startLine/startColumn the position of the token being scanned.
+	lineStart              int
+	startLine, startColumn int
+	reporter               errors.Reporter
 }
 
-func New(source string) Scanner {
+// New registers src under name in files and returns a scanner for it. Spans
+// of the scanned tokens refer to the registered file.
+func New(files *source.FileSet, name, src string, reporter errors.Reporter) Scanner {
+	file := files.AddFile(name, src)
+	runes := []rune(src)
+	offsets := make([]int, 0, len(runes)+1)
+	for offset := range src {
+		offsets = append(offsets, offset)
+	}
+	offsets = append(offsets, len(src))
+
 	return Scanner{
-		source:  []rune(source),
-		tokens:  make([]token.Token, 0),
-		start:   0,
-		current: 0,
-		line:    1,
+		file:     file,
+		reporter: reporter,
+		source:   runes,
+		offsets:  offsets,
+		tokens:   make([]token.Token, 0),
+		start:    0,
+		current:  0,
+		line:     1,
 	}
 }
 
 func (s *Scanner) ScanTokens() []token.Token {
 	for !s.isAtEnd() {
 		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.current - s.lineStart + 1
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, token.New(token.EOF, "", nil, s.line))
+	s.start = s.current
+	s.startLine = s.line
+	s.startColumn = s.current - s.lineStart + 1
+	s.tokens = append(s.tokens, token.New(token.EOF, "", nil, s.line, s.span()))
 	return s.tokens
 }
 
@@ -44,6 +75,12 @@ func (s *Scanner) scanToken() {
 		s.addToken(token.LEFT_BRACE)
 	case '}':
 		s.addToken(token.RIGHT_BRACE)
+	case '[':
+		s.addToken(token.LEFT_BRACKET)
+	case ']':
+		s.addToken(token.RIGHT_BRACKET)
+	case ':':
+		s.addToken(token.COLON)
 	case ',':
 		s.addToken(token.COMMA)
 	case '.':
@@ -95,7 +132,7 @@ func (s *Scanner) scanToken() {
 	case '\t':
 		break
 	case '\n':
-		s.line++
+		s.newLine()
 	case '"':
 		s.scanString()
 	default:
@@ -104,7 +141,7 @@ func (s *Scanner) scanToken() {
 		} else if isAlpha(c) {
 			s.scanIdentifier()
 		} else {
-			errors.Error(s.line, "Unexpected character.")
+			s.error("Unexpected character.")
 		}
 	}
 
@@ -116,7 +153,40 @@ func (s *Scanner) addToken(token token.TokenType) {
 
 func (s *Scanner) appendToken(tokenType token.TokenType, literal any) {
 	text := s.source[s.start:s.current]
-	s.tokens = append(s.tokens, token.New(tokenType, string(text), literal, s.line))
+	s.tokens = append(s.tokens, token.New(tokenType, string(text), literal, s.line, s.span()))
+}
+
+// span covers the token being scanned, from its first rune up to current.
+func (s *Scanner) span() token.Span {
+	return token.Span{
+		File:   s.file.ID(),
+		Line:   s.startLine,
+		Column: s.startColumn,
+		Start:  s.offsets[s.start],
+		End:    s.offsets[s.current],
+	}
+}
+
+func (s *Scanner) newLine() {
+	s.line++
+	s.lineStart = s.current
+}
+
+func (s *Scanner) error(message string) {
+	s.reporter.Report(errors.At(s.span(), errors.CodeScan, message))
+}
+
+// errorAt reports message at the runes from source[from] up to current,
+// which must lie on the current line.
+func (s *Scanner) errorAt(from int, message string) {
+	span := token.Span{
+		File:   s.file.ID(),
+		Line:   s.line,
+		Column: from - s.lineStart + 1,
+		Start:  s.offsets[from],
+		End:    s.offsets[s.current],
+	}
+	s.reporter.Report(errors.At(span, errors.CodeScan, message))
 }
 
 func (s *Scanner) advance() rune {
@@ -125,6 +195,10 @@ func (s *Scanner) advance() rune {
 	return c
 }
 
+func (s *Scanner) previous() rune {
+	return s.source[s.current-1]
+}
+
 func (s *Scanner) match(expected rune) bool {
 	if s.isAtEnd() {
 		return false
@@ -155,24 +229,94 @@ func (s *Scanner) peekNext() rune {
 	return s.source[s.current+1]
 }
 
+// scanString scans a string literal. The token's literal is the string
+// with escape sequences decoded, while its lexeme keeps them as written.
 func (s *Scanner) scanString() {
+	var value strings.Builder
 	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+		c := s.advance()
+		switch c {
+		case '\\':
+			s.scanEscape(&value)
+		case '\n':
+			s.newLine()
+			value.WriteRune(c)
+		default:
+			value.WriteRune(c)
 		}
-		s.advance()
 	}
 
 	if s.isAtEnd() {
-		errors.Error(s.line, "Unterminated string")
+		s.error("Unterminated string")
 		return
 	}
 
 	// The closing ".
 	s.advance()
 
-	var value string = string(s.source[s.start+1 : s.current-1])
-	s.appendToken(token.STRING, value)
+	s.appendToken(token.STRING, value.String())
+}
+
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'\\': '\\',
+	'"':  '"',
+	'0':  0,
+}
+
+// scanEscape decodes the escape sequence after a backslash into value. An
+// invalid escape is reported with the span of the whole sequence and left
+// out of value.
+func (s *Scanner) scanEscape(value *strings.Builder) {
+	from := s.current - 1
+	if s.isAtEnd() {
+		return
+	}
+
+	c := s.advance()
+	if decoded, ok := escapes[c]; ok {
+		value.WriteRune(decoded)
+		return
+	}
+	if c == 'u' {
+		s.scanUnicodeEscape(from, value)
+		return
+	}
+
+	if c == '\n' {
+		s.errorAt(from, "Invalid escape sequence at end of line.")
+		s.newLine()
+		return
+	}
+	s.errorAt(from, fmt.Sprintf("Invalid escape sequence '\\%c'.", c))
+}
+
+// scanUnicodeEscape decodes the rest of \u{XXXX}, one to six hex digits
+// naming a Unicode code point. from is the index of the backslash.
+func (s *Scanner) scanUnicodeEscape(from int, value *strings.Builder) {
+	if !s.match('{') {
+		s.errorAt(from, "Unicode escape must look like '\\u{1F600}'.")
+		return
+	}
+	digitsStart := s.current
+	for isHexDigit(s.peek()) {
+		s.advance()
+	}
+	digits := string(s.source[digitsStart:s.current])
+	if !s.match('}') || len(digits) == 0 || len(digits) > 6 {
+		s.errorAt(from, "Unicode escape must look like '\\u{1F600}'.")
+		return
+	}
+
+	code, _ := strconv.ParseUint(digits, 16, 32)
+	r := rune(code)
+	if !utf8.ValidRune(r) {
+		s.errorAt(from, fmt.Sprintf("Invalid Unicode code point '\\u{%s}'.", digits))
+		return
+	}
+	value.WriteRune(r)
 }
 
 func (s *Scanner) scanNumber() {
@@ -190,7 +334,9 @@ func (s *Scanner) scanNumber() {
 	var valueStr string = string(s.source[s.start:s.current])
 	valueNum, err := strconv.ParseFloat(valueStr, 64)
 	if err != nil {
-		panic(err)
+		// The syntax is valid, so the literal must be too large. The token
+		// is still added so that parsing carries on normally.
+		s.error("Number literal out of range.")
 	}
 	s.appendToken(token.NUMBER, valueNum)
 }
@@ -220,3 +366,7 @@ func isAlpha(c rune) bool {
 func isAlphaNumeric(c rune) bool {
 	return isAlpha(c) || isDigit(c)
 }
+
+func isHexDigit(c rune) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,107 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/source"
+	"github.com/anwprath/glox/token"
+)
+
+func scan(src string) ([]token.Token, []errors.Diagnostic) {
+	reporter := &errors.Collector{}
+	sc := New(source.NewFileSet(), "test", src, reporter)
+	return sc.ScanTokens(), reporter.Diagnostics
+}
+
+func TestNumberOutOfRange(t *testing.T) {
+	src := "var x = " + strings.Repeat("9", 400) + ";"
+	tokens, diagnostics := scan(src)
+	if len(diagnostics) != 1 || diagnostics[0].Message != "Number literal out of range." {
+		t.Fatalf("got diagnostics %v, want one out of range error", diagnostics)
+	}
+	if span := diagnostics[0].Span; span.Line != 1 || span.Column != 9 {
+		t.Errorf("error at %d:%d, want 1:9", span.Line, span.Column)
+	}
+	// The literal still yields a token, so parsing is not thrown off.
+	if len(tokens) != 6 || tokens[3].TokenType != token.NUMBER {
+		t.Errorf("got tokens %v", tokens)
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`"a\nb"`, "a\nb"},
+		{`"\t\r"`, "\t\r"},
+		{`"back\\slash"`, `back\slash`},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"nul\0"`, "nul\x00"},
+		{`"\u{41}\u{e9}"`, "Aé"},
+		{`"\u{1F600}"`, "😀"},
+		{`"\u{10FFFF}"`, "\U0010FFFF"},
+		{`"\\n"`, `\n`},
+		{"\"two\nlines\"", "two\nlines"},
+	}
+	for _, tt := range tests {
+		tokens, diagnostics := scan(tt.src)
+		if len(diagnostics) > 0 {
+			t.Errorf("%s: unexpected diagnostics %v", tt.src, diagnostics)
+			continue
+		}
+		if tokens[0].TokenType != token.STRING || tokens[0].Literal != tt.want {
+			t.Errorf("%s: got %v, want %q", tt.src, tokens[0], tt.want)
+		}
+	}
+}
+
+func TestInvalidEscapes(t *testing.T) {
+	tests := []struct {
+		src          string
+		message      string
+		line, column int
+		// width is the length of the underlined span, in bytes.
+		width int
+	}{
+		{`"a\qb"`, `Invalid escape sequence '\q'.`, 1, 3, 2},
+		{"print 1;\n  \"x\\u{110000}\";", `Invalid Unicode code point '\u{110000}'.`, 2, 5, 10},
+		{`"\u{D800}"`, `Invalid Unicode code point '\u{D800}'.`, 1, 2, 8},
+		{`"\u{41"`, `Unicode escape must look like '\u{1F600}'.`, 1, 2, 5},
+		{`"\u41"`, `Unicode escape must look like '\u{1F600}'.`, 1, 2, 2},
+		{`"\u{}"`, `Unicode escape must look like '\u{1F600}'.`, 1, 2, 4},
+		{`"\u{1234567}"`, `Unicode escape must look like '\u{1F600}'.`, 1, 2, 11},
+		{"\"a\\\nb\"", "Invalid escape sequence at end of line.", 1, 3, 2},
+	}
+	for _, tt := range tests {
+		_, diagnostics := scan(tt.src)
+		if len(diagnostics) != 1 {
+			t.Errorf("%s: got diagnostics %v, want one", tt.src, diagnostics)
+			continue
+		}
+		d := diagnostics[0]
+		if d.Message != tt.message || d.Span.Line != tt.line || d.Span.Column != tt.column || d.Span.End-d.Span.Start != tt.width {
+			t.Errorf("%s: got %q at %d:%d width %d, want %q at %d:%d width %d", tt.src,
+				d.Message, d.Span.Line, d.Span.Column, d.Span.End-d.Span.Start,
+				tt.message, tt.line, tt.column, tt.width)
+		}
+	}
+}
+
+func TestUnterminatedUnicodeEscape(t *testing.T) {
+	_, diagnostics := scan(`"\u{`)
+	want := []string{`Unicode escape must look like '\u{1F600}'.`, "Unterminated string"}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("got diagnostics %v, want %q", diagnostics, want)
+	}
+	for i, d := range diagnostics {
+		if d.Message != want[i] || d.Span.Line != 1 {
+			t.Errorf("diagnostic %d: got %q on line %d, want %q on line 1", i, d.Message, d.Span.Line, want[i])
+		}
+	}
+	if column := diagnostics[0].Span.Column; column != 2 {
+		t.Errorf("escape error at column %d, want 2", column)
+	}
+}
@@ -0,0 +1,99 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// File is one registered source text.
+type File struct {
+	id      int
+	Name    string
+	Content string
+	// lines holds the byte offset of the first character of each line.
+	lines []int
+}
+
+func (f *File) ID() int {
+	return f.id
+}
+
+// Line returns the text of the 1-based line n without its line terminator, or
+// "" if n is out of range.
+func (f *File) Line(n int) string {
+	if n < 1 || n > len(f.lines) {
+		return ""
+	}
+	start := f.lines[n-1]
+	end := len(f.Content)
+	if n < len(f.lines) {
+		end = f.lines[n] - 1
+	}
+	return strings.TrimRight(f.Content[start:end], "\r")
+}
+
+// Position is a human readable source location.
+type Position struct {
+	Filename     string
+	Line, Column int
+}
+
+func (p Position) String() string {
+	name := p.Filename
+	if name == "" {
+		name = "<unknown>"
+	}
+	return fmt.Sprintf("%s:%d:%d", name, p.Line, p.Column)
+}
+
+// FileSet is a registry of every source file read during a run, so that
+// positions from any of them can be turned back into names and text. IDs
+// start at 1; 0 means "no file". It is safe for concurrent use.
+type FileSet struct {
+	mu    sync.RWMutex
+	files []*File
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{}
+}
+
+func (fs *FileSet) AddFile(name, content string) *File {
+	lines := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	file := &File{
+		id:      len(fs.files) + 1,
+		Name:    name,
+		Content: content,
+		lines:   lines,
+	}
+	fs.files = append(fs.files, file)
+	return file
+}
+
+// File returns the file registered under id, or nil.
+func (fs *FileSet) File(id int) *File {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	if id < 1 || id > len(fs.files) {
+		return nil
+	}
+	return fs.files[id-1]
+}
+
+// Position resolves line and column in the file registered under id.
+func (fs *FileSet) Position(id, line, column int) Position {
+	pos := Position{Line: line, Column: column}
+	if file := fs.File(id); file != nil {
+		pos.Filename = file.Name
+	}
+	return pos
+}
@@ -0,0 +1,107 @@
+package stdlib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FS is the file system access granted to a script.
+type FS struct {
+	// root confines access to one directory tree. Empty means unconfined.
+	root     string
+	writable bool
+}
+
+// ReadOnly lets scripts read files below root. Relative paths are resolved
+// against root.
+func ReadOnly(root string) *FS {
+	return &FS{root: absolute(root)}
+}
+
+// ReadWrite lets scripts read and write files below root. Relative paths
+// are resolved against root.
+func ReadWrite(root string) *FS {
+	return &FS{root: absolute(root), writable: true}
+}
+
+func absolute(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func installFS(d Definer, fs *FS) {
+	define(d, "readFile", func(path string) (string, error) {
+		data, err := fs.readFile(path)
+		return string(data), err
+	})
+	define(d, "writeFile", fs.writeFile)
+}
+
+func (fs *FS) readFile(path string) ([]byte, error) {
+	if fs.root == "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read '%s'.", path)
+		}
+		return data, nil
+	}
+
+	name, err := fs.relative(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(fs.root)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read '%s'.", path)
+	}
+	defer root.Close()
+	data, err := root.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read '%s'.", path)
+	}
+	return data, nil
+}
+
+func (fs *FS) writeFile(path, content string) error {
+	if !fs.writable {
+		return fmt.Errorf("Access to '%s' is not permitted: the file system is read-only.", path)
+	}
+	if fs.root == "" {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("Could not write '%s'.", path)
+		}
+		return nil
+	}
+
+	name, err := fs.relative(path)
+	if err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(fs.root)
+	if err != nil {
+		return fmt.Errorf("Could not write '%s'.", path)
+	}
+	defer root.Close()
+	if err := root.WriteFile(name, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("Could not write '%s'.", path)
+	}
+	return nil
+}
+
+// relative returns path relative to the root, or an error if it lies
+// outside. os.Root additionally guards against escaping through symlinks.
+func (fs *FS) relative(path string) (string, error) {
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(fs.root, full)
+	}
+	name, err := filepath.Rel(fs.root, filepath.Clean(full))
+	if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Access to '%s' is not permitted.", path)
+	}
+	return name, nil
+}
@@ -0,0 +1,94 @@
+package stdlib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sandboxDir returns a root directory holding in.txt, next to a sibling
+// directory holding out.txt.
+func sandboxDir(t *testing.T) (root, outside string) {
+	t.Helper()
+	dir := t.TempDir()
+	root = filepath.Join(dir, "root")
+	outside = filepath.Join(dir, "outside")
+	for _, path := range []string{root, outside} {
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "in.txt"), []byte("inside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "out.txt"), []byte("outside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root, outside
+}
+
+func TestReadOnly(t *testing.T) {
+	root, _ := sandboxDir(t)
+	d := install(Capabilities{FS: ReadOnly(root)})
+
+	for _, path := range []string{"in.txt", "./in.txt", filepath.Join(root, "in.txt")} {
+		if got, err := d.call("readFile", path); err != nil || got != "inside" {
+			t.Errorf("readFile(%q) = %v, %v; want inside", path, got, err)
+		}
+	}
+
+	for _, path := range []string{"in.txt", "new.txt"} {
+		_, err := d.call("writeFile", path, "changed")
+		if err == nil || !strings.Contains(err.Error(), "read-only") {
+			t.Errorf("writeFile(%q) = %v, want a read-only error", path, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "in.txt")); string(data) != "inside" {
+		t.Errorf("in.txt now holds %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "new.txt")); err == nil {
+		t.Error("writeFile created new.txt in a read-only file system")
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	root, _ := sandboxDir(t)
+	d := install(Capabilities{FS: ReadWrite(root)})
+
+	if _, err := d.call("writeFile", "new.txt", "written"); err != nil {
+		t.Fatalf("writeFile(new.txt) = %v", err)
+	}
+	if got, err := d.call("readFile", "new.txt"); err != nil || got != "written" {
+		t.Errorf("readFile(new.txt) = %v, %v; want written", got, err)
+	}
+}
+
+func TestPathsOutsideRoot(t *testing.T) {
+	root, outside := sandboxDir(t)
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	d := install(Capabilities{FS: ReadWrite(root)})
+
+	for _, path := range []string{
+		"../outside/out.txt",
+		"sub/../../outside/out.txt",
+		filepath.Join(outside, "out.txt"),
+		filepath.Join(root, "..", "outside", "out.txt"),
+		"link/out.txt",
+	} {
+		if got, err := d.call("readFile", path); err == nil {
+			t.Errorf("readFile(%q) = %q, want an error", path, got)
+		}
+		if _, err := d.call("writeFile", path, "escaped"); err == nil {
+			t.Errorf("writeFile(%q) succeeded, want an error", path)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(outside, "out.txt")); string(data) != "outside" {
+		t.Errorf("out.txt now holds %q", data)
+	}
+	if _, err := d.call("readFile", "../outside/out.txt"); err == nil || err.Error() != "Access to '../outside/out.txt' is not permitted." {
+		t.Errorf("got %v, want an access error naming the path", err)
+	}
+}
@@ -0,0 +1,26 @@
+package stdlib
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// installMath defines the math built-ins. They are pure apart from random,
+// so they need no capability.
+func installMath(d Definer, source rand.Source) {
+	if source == nil {
+		source = rand.NewPCG(rand.Uint64(), rand.Uint64())
+	}
+	random := rand.New(source)
+
+	d.DefineGlobal("pi", math.Pi)
+	define(d, "sqrt", math.Sqrt)
+	define(d, "floor", math.Floor)
+	define(d, "ceil", math.Ceil)
+	define(d, "abs", math.Abs)
+	define(d, "pow", math.Pow)
+	define(d, "min", math.Min)
+	define(d, "max", math.Max)
+	// random returns a number in [0, 1).
+	define(d, "random", random.Float64)
+}
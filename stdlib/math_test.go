@@ -0,0 +1,67 @@
+package stdlib
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestMath(t *testing.T) {
+	d := install(Capabilities{})
+	if d["pi"] != math.Pi {
+		t.Errorf("pi = %v", d["pi"])
+	}
+
+	tests := []struct {
+		name string
+		args []any
+		want float64
+	}{
+		{"sqrt", []any{16.0}, 4},
+		{"floor", []any{-1.5}, -2},
+		{"ceil", []any{1.2}, 2},
+		{"abs", []any{-3.0}, 3},
+		{"pow", []any{2.0, 10.0}, 1024},
+		{"min", []any{2.0, -1.0}, -1},
+		{"max", []any{2.0, -1.0}, 2},
+	}
+	for _, tt := range tests {
+		if got, err := d.call(tt.name, tt.args...); err != nil || got != tt.want {
+			t.Errorf("%s%v = %v, %v; want %v", tt.name, tt.args, got, err, tt.want)
+		}
+	}
+
+	if got, _ := d.call("sqrt", -1.0); !math.IsNaN(got.(float64)) {
+		t.Errorf("sqrt(-1) = %v, want NaN", got)
+	}
+	if _, err := d.call("sqrt", "4"); err == nil {
+		t.Error(`sqrt("4") succeeded, want an argument error`)
+	}
+}
+
+func TestRandomSeed(t *testing.T) {
+	sequence := func(seed uint64) []float64 {
+		d := install(Capabilities{Random: rand.NewPCG(seed, seed)})
+		numbers := make([]float64, 5)
+		for i := range numbers {
+			n, err := d.call("random")
+			if err != nil {
+				t.Fatal(err)
+			}
+			numbers[i] = n.(float64)
+			if numbers[i] < 0 || numbers[i] >= 1 {
+				t.Errorf("random() = %v, want a number in [0, 1)", numbers[i])
+			}
+		}
+		return numbers
+	}
+
+	first, second, other := sequence(42), sequence(42), sequence(7)
+	if !slices.Equal(first, second) {
+		t.Errorf("seed 42 gave %v, then %v", first, second)
+	}
+	if slices.Equal(first, other) {
+		t.Errorf("seeds 42 and 7 both gave %v", first)
+	}
+}
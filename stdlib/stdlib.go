@@ -0,0 +1,77 @@
+// Package stdlib provides glox's built-in functions. Built-ins that reach
+// outside the script, such as the file system or the clock, are only
+// installed when the corresponding capability is granted.
+package stdlib
+
+import (
+	"math/rand/v2"
+
+	"github.com/anwprath/glox/native"
+	"github.com/anwprath/glox/object"
+)
+
+// Definer is implemented by both interpreter.Interpreter and vm.VM.
+type Definer interface {
+	DefineGlobal(name string, value any)
+	DefineFunction(function *native.Function)
+}
+
+// Capabilities lists what the built-ins of a script may access. The zero
+// value is a sandbox without any of them.
+type Capabilities struct {
+	// FS enables readFile and writeFile. Nil denies all file access.
+	FS *FS
+	// Env enables getenv.
+	Env bool
+	// Clock enables clock.
+	Clock bool
+	// Process enables exit.
+	Process bool
+	// Random is the source of random(). Nil seeds one randomly; set it to
+	// make runs reproducible.
+	Random rand.Source
+}
+
+// Full grants every capability, as the glox command line does.
+func Full() Capabilities {
+	return Capabilities{FS: &FS{writable: true}, Env: true, Clock: true, Process: true}
+}
+
+// Install defines the math and string built-ins and those allowed by caps
+// on d.
+func Install(d Definer, caps Capabilities) {
+	installMath(d, caps.Random)
+	installStrings(d)
+	if caps.FS != nil {
+		installFS(d, caps.FS)
+	}
+	if caps.Env {
+		installEnv(d)
+	}
+	if caps.Clock {
+		installClock(d)
+	}
+	if caps.Process {
+		installProcess(d)
+	}
+}
+
+// define wraps fn with native.Wrap and defines it on d. The strings and
+// lists it returns are charged like those the script builds itself. Built-in
+// signatures are fixed, so a failure to wrap is a bug in this package.
+func define(d Definer, name string, fn any) {
+	function, err := native.Wrap(name, fn)
+	if err != nil {
+		panic(err)
+	}
+	d.DefineFunction(native.NewAllocating(name, function.Arity(), func(alloc native.Allocator, args []any) (any, error) {
+		result, err := function.Invoke(alloc, args)
+		if err != nil {
+			return nil, err
+		}
+		if err := alloc.Allocate(object.SizeOf(result)); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}))
+}
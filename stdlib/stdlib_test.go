@@ -0,0 +1,77 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/anwprath/glox/native"
+)
+
+// definer records the built-ins installed on it.
+type definer map[string]any
+
+func (d definer) DefineGlobal(name string, value any) {
+	d[name] = value
+}
+
+func (d definer) DefineFunction(function *native.Function) {
+	d[function.Name()] = function
+}
+
+func install(caps Capabilities) definer {
+	d := definer{}
+	Install(d, caps)
+	return d
+}
+
+// call invokes the built-in name as a script would.
+func (d definer) call(name string, args ...any) (any, error) {
+	return d[name].(*native.Function).Invoke(nil, args)
+}
+
+func TestCapabilities(t *testing.T) {
+	gated := []struct {
+		caps  Capabilities
+		names []string
+	}{
+		{Capabilities{FS: ReadOnly(t.TempDir())}, []string{"readFile", "writeFile"}},
+		{Capabilities{Env: true}, []string{"getenv"}},
+		{Capabilities{Clock: true}, []string{"clock"}},
+		{Capabilities{Process: true}, []string{"exit"}},
+	}
+
+	sandbox := install(Capabilities{})
+	for _, name := range []string{"len", "substr", "sqrt", "random", "pi"} {
+		if _, ok := sandbox[name]; !ok {
+			t.Errorf("%s is missing from the sandbox", name)
+		}
+	}
+	full := install(Full())
+	for _, capability := range gated {
+		d := install(capability.caps)
+		if len(d) != len(sandbox)+len(capability.names) {
+			t.Errorf("%v defines %d built-ins, want %d", capability.names, len(d), len(sandbox)+len(capability.names))
+		}
+		for _, name := range capability.names {
+			if _, ok := sandbox[name]; ok {
+				t.Errorf("%s is defined in the sandbox", name)
+			}
+			if _, ok := d[name]; !ok {
+				t.Errorf("%s is not defined when its capability is granted", name)
+			}
+			if _, ok := full[name]; !ok {
+				t.Errorf("%s is not defined by Full()", name)
+			}
+		}
+	}
+}
+
+func TestGetenv(t *testing.T) {
+	t.Setenv("GLOX_TEST", "value")
+	d := install(Capabilities{Env: true})
+	if got, err := d.call("getenv", "GLOX_TEST"); err != nil || got != "value" {
+		t.Errorf("getenv(GLOX_TEST) = %v, %v", got, err)
+	}
+	if got, err := d.call("getenv", "GLOX_TEST_UNSET"); err != nil || got != nil {
+		t.Errorf("getenv(GLOX_TEST_UNSET) = %v, %v; want nil", got, err)
+	}
+}
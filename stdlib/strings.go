@@ -0,0 +1,118 @@
+package stdlib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anwprath/glox/object"
+)
+
+// installStrings defines the string built-ins. Lengths and positions count
+// Unicode code points, not bytes, the same as s[i].
+func installStrings(d Definer) {
+	define(d, "len", length)
+	define(d, "substr", substr)
+	define(d, "indexOf", indexOf)
+	define(d, "split", split)
+	define(d, "join", join)
+	define(d, "upper", strings.ToUpper)
+	define(d, "lower", strings.ToLower)
+	define(d, "trim", strings.TrimSpace)
+	define(d, "replace", strings.ReplaceAll)
+	define(d, "toNumber", toNumber)
+	define(d, "toString", object.Stringify)
+}
+
+func length(value any) (float64, error) {
+	switch value := value.(type) {
+	case string:
+		return float64(utf8.RuneCountInString(value)), nil
+	case *object.List:
+		return float64(len(value.Elements)), nil
+	case *object.Map:
+		return float64(value.Len()), nil
+	}
+	return 0, fmt.Errorf("Argument 1 to 'len' must be a string, a list or a map.")
+}
+
+// substr returns length code points of s starting at start.
+func substr(s string, start, length int) (string, error) {
+	runes := []rune(s)
+	// Comparing against the remaining length cannot overflow, unlike
+	// start+length.
+	if start < 0 || length < 0 || start > len(runes) || length > len(runes)-start {
+		return "", fmt.Errorf("Substring out of range.")
+	}
+	return string(runes[start : start+length]), nil
+}
+
+// indexOf returns the position of the first sub in s, or -1.
+func indexOf(s, sub string) float64 {
+	i := strings.Index(s, sub)
+	if i < 0 {
+		return -1
+	}
+	return float64(utf8.RuneCountInString(s[:i]))
+}
+
+// split cuts s around each sep. An empty sep splits s into code points.
+func split(s, sep string) *object.List {
+	parts := strings.Split(s, sep)
+	elements := make([]any, len(parts))
+	for i, part := range parts {
+		elements[i] = part
+	}
+	return object.NewList(elements)
+}
+
+// join concatenates the elements of a list, formatted as print would,
+// with sep between them.
+func join(value any, sep string) (string, error) {
+	list, ok := value.(*object.List)
+	if !ok {
+		return "", fmt.Errorf("Argument 1 to 'join' must be a list.")
+	}
+	parts := make([]string, len(list.Elements))
+	for i, element := range list.Elements {
+		parts[i] = object.Stringify(element)
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// toNumber parses s as a number, returning nil if it is not one. Only the
+// decimal syntax of Lox number literals is accepted, optionally preceded by
+// a minus sign and surrounded by spaces, so "0x10", "NaN" and "Infinity"
+// are not numbers.
+func toNumber(s string) any {
+	s = strings.TrimSpace(s)
+	if !isNumberLiteral(strings.TrimPrefix(s, "-")) {
+		return nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		// Out of range.
+		return nil
+	}
+	return n
+}
+
+// isNumberLiteral reports whether s matches digit+ ( "." digit+ )?, the
+// grammar scanned by scanner.scanNumber.
+func isNumberLiteral(s string) bool {
+	integer, fraction, hasPoint := strings.Cut(s, ".")
+	return isDigits(integer) && (!hasPoint || isDigits(fraction))
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package stdlib
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSubstr(t *testing.T) {
+	tests := []struct {
+		s             string
+		start, length int
+		want          string
+		wantErr       bool
+	}{
+		{"héllo", 1, 3, "éll", false},
+		{"abc", 3, 0, "", false},
+		{"abc", 0, 4, "", true},
+		{"abc", 4, 0, "", true},
+		{"abc", -1, 1, "", true},
+		{"abc", 1, -1, "", true},
+		{"abc", 1 << 62, 1 << 62, "", true},
+		{"abc", 1, math.MaxInt, "", true},
+		{"abc", math.MaxInt, math.MaxInt, "", true},
+	}
+	for _, tt := range tests {
+		got, err := substr(tt.s, tt.start, tt.length)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("substr(%q, %d, %d) = %q, %v; want %q, error %v",
+				tt.s, tt.start, tt.length, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSubstrHugeBoundsFromLox(t *testing.T) {
+	d := install(Capabilities{})
+	huge := float64(1 << 62)
+	if _, err := d.call("substr", "abc", huge, huge); err == nil {
+		t.Error("substr with huge bounds succeeded, want an error")
+	}
+}
+
+func TestToNumber(t *testing.T) {
+	tests := []struct {
+		s    string
+		want any
+	}{
+		{"12", 12.0},
+		{" 3.5 ", 3.5},
+		{"-2", -2.0},
+		{"007", 7.0},
+		{"1.", nil},
+		{".5", nil},
+		{"", nil},
+		{"-", nil},
+		{"+1", nil},
+		{"1e3", nil},
+		{"0x1p4", nil},
+		{"NaN", nil},
+		{"Infinity", nil},
+		{"inf", nil},
+		{"1_000", nil},
+		{"1" + strings.Repeat("0", 400), nil},
+	}
+	for _, tt := range tests {
+		if got := toNumber(tt.s); got != tt.want {
+			t.Errorf("toNumber(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
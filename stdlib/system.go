@@ -0,0 +1,28 @@
+package stdlib
+
+import (
+	"os"
+	"time"
+)
+
+func installEnv(d Definer) {
+	define(d, "getenv", func(name string) any {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return nil
+	})
+}
+
+func installClock(d Definer) {
+	// Seconds since the Unix epoch, like reference Lox.
+	define(d, "clock", func() float64 {
+		return float64(time.Now().UnixNano()) / float64(time.Second)
+	})
+}
+
+func installProcess(d Definer) {
+	define(d, "exit", func(code int) {
+		os.Exit(code)
+	})
+}
@@ -0,0 +1,33 @@
+package token
+
+// Span locates a piece of source text. File is the ID of the file in its
+// source.FileSet. Line and Column are 1-based, with Column counted in runes;
+// Start and End are byte offsets into the file, End being exclusive. The zero
+// Span means "no position".
+type Span struct {
+	File         int
+	Line, Column int
+	Start, End   int
+}
+
+func (s Span) IsZero() bool {
+	return s == Span{}
+}
+
+// Merge returns the smallest span covering both s and other. Zero spans are
+// ignored.
+func (s Span) Merge(other Span) Span {
+	if s.IsZero() {
+		return other
+	}
+	if other.IsZero() || other.File != s.File {
+		return s
+	}
+
+	merged := s
+	if other.Start < s.Start {
+		merged.Line, merged.Column, merged.Start = other.Line, other.Column, other.Start
+	}
+	merged.End = max(s.End, other.End)
+	return merged
+}
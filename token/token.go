@@ -7,14 +7,16 @@ type Token struct {
 	Lexeme    string
 	Literal   any
 	Line      int
+	Span      Span
 }
 
-func New(tokenType TokenType, lexeme string, literal any, line int) Token {
+func New(tokenType TokenType, lexeme string, literal any, line int, span Span) Token {
 	return Token{
 		TokenType: tokenType,
 		Lexeme:    lexeme,
 		Literal:   literal,
 		Line:      line,
+		Span:      span,
 	}
 }
 
@@ -20,21 +20,41 @@ func main() {
 	outputDir := args[1]
 
 	defineAst(outputDir, "Expr", []string{
+		"Assign   : token.Token Name, Expr Value",
 		"Binary   : Expr Left, token.Token Operator, Expr Right",
+		"Call     : Expr Callee, token.Token Paren, []Expr Arguments",
+		"Get      : Expr Object, token.Token Name",
 		"Grouping : Expr Expression",
-		"Literal  : any Value",
+		"Index    : Expr Object, token.Token Bracket, Expr Index",
+		"IndexSet : Expr Object, token.Token Bracket, Expr Index, Expr Value",
+		"ListLiteral : token.Token Bracket, []Expr Elements",
+		"Literal  : any Value, token.Token Token",
+		"Logical  : Expr Left, token.Token Operator, Expr Right",
+		"MapLiteral : token.Token Brace, []Expr Keys, []Expr Values",
+		"Set      : Expr Object, token.Token Name, Expr Value",
+		"Slice    : Expr Object, token.Token Bracket, Expr Start, Expr End",
+		"Super    : token.Token Keyword, token.Token Method",
+		"This     : token.Token Keyword",
 		"Unary    : token.Token Operator, Expr Right",
+		"Variable : token.Token Name",
 	})
 
 	defineAst(outputDir, "Stmt", []string{
+		"Block      : []Stmt Statements",
+		"Class      : token.Token Name, *Variable Superclass, []*Function Methods",
 		"Expression : Expr Expression",
+		"Function   : token.Token Name, []token.Token Params, []Stmt Body",
+		"If         : Expr Condition, Stmt ThenBranch, Stmt ElseBranch",
 		"Print      : Expr Expression",
+		"Return     : token.Token Keyword, Expr Value",
+		"Var        : token.Token Name, Expr Initializer",
+		"While      : token.Token Keyword, Expr Condition, Stmt Body",
 	})
 }
 
 func defineAst(outputDir, baseName string, types []string) {
-	defer func() { exec.Command("gofmt", "-w", "ast/"+baseName+".go").Run() }()
-	outPath := fmt.Sprintf("%s/%s.go", outputDir, baseName)
+	outPath := fmt.Sprintf("%s/%s.go", outputDir, strings.ToLower(baseName))
+	defer func() { exec.Command("gofmt", "-w", outPath).Run() }()
 
 	// Create directory
 	os.MkdirAll(filepath.Dir(outPath), 0755)
@@ -49,7 +69,7 @@ func defineAst(outputDir, baseName string, types []string) {
 	w := bufio.NewWriter(exprGo)
 	fmt.Fprintf(w, "package ast\n\n")
 	fmt.Fprintf(w, "import \"github.com/anwprath/glox/token\"\n\n")
-	fmt.Fprintf(w, "type %s interface{\nAccept(v %sVisitor) (any, error)\n}\n\n", baseName, baseName)
+	fmt.Fprintf(w, "type %s interface{\nAccept(v %sVisitor) (any, error)\nSpan() token.Span\n}\n\n", baseName, baseName)
 
 	defineVisitorInterface(w, baseName, types)
 
@@ -76,8 +96,31 @@ func defineType(w *bufio.Writer, baseName, className, fieldList string) {
 		fmt.Fprintf(w, " %s %s\n", memberName, memberType)
 	}
 	fmt.Fprintf(w, "}\n\n")
-	fmt.Fprintf(w, "func (node *%s)Accept(v %sVisitor) (any, error) {\n return v.Visit%sExpr(node)}\n", className, baseName, className)
+	fmt.Fprintf(w, "func (node *%s)Accept(v %sVisitor) (any, error) {\n return v.Visit%s%s(node)}\n\n", className, baseName, className, baseName)
 
+	defineSpan(w, className, fields)
+}
+
+// defineSpan emits a Span method merging the spans of every positioned field
+// of the node. Fields of type any carry no position and are skipped.
+func defineSpan(w *bufio.Writer, className string, fields []string) {
+	fmt.Fprintf(w, "func (node *%s) Span() token.Span {\nvar span token.Span\n", className)
+	for _, member := range fields {
+		memberName := strings.Split(member, " ")[1]
+		memberType := strings.Split(member, " ")[0]
+		switch {
+		case memberType == "any":
+		case memberType == "token.Token":
+			fmt.Fprintf(w, "span = span.Merge(node.%s.Span)\n", memberName)
+		case memberType == "[]token.Token":
+			fmt.Fprintf(w, "for _, t := range node.%s {\nspan = span.Merge(t.Span)\n}\n", memberName)
+		case strings.HasPrefix(memberType, "[]"):
+			fmt.Fprintf(w, "for _, n := range node.%s {\nspan = span.Merge(n.Span())\n}\n", memberName)
+		default:
+			fmt.Fprintf(w, "if node.%s != nil {\nspan = span.Merge(node.%s.Span())\n}\n", memberName, memberName)
+		}
+	}
+	fmt.Fprintf(w, "return span\n}\n\n")
 }
 
 func defineVisitorInterface(w *bufio.Writer, baseName string, types []string) {
@@ -85,7 +128,7 @@ func defineVisitorInterface(w *bufio.Writer, baseName string, types []string) {
 
 	for _, t := range types {
 		className := strings.TrimSpace(strings.Split(t, ":")[0])
-		fmt.Fprintf(w, "Visit%s%s(expr *%s) (any, error)\n", className, baseName, className)
+		fmt.Fprintf(w, "Visit%s%s(%s *%s) (any, error)\n", className, baseName, strings.ToLower(baseName), className)
 	}
 	fmt.Fprintf(w, "}\n\n")
 
@@ -0,0 +1,51 @@
+package vm
+
+import (
+	"github.com/anwprath/glox/compiler"
+)
+
+// Closure is a compiled function together with the variables it captured.
+type Closure struct {
+	Function *compiler.Function
+	Upvalues []*Upvalue
+}
+
+func (c *Closure) String() string {
+	return c.Function.String()
+}
+
+// Upvalue is a captured variable. While open it refers to a live stack
+// slot; once that slot goes out of scope the value moves into closed.
+type Upvalue struct {
+	slot   int
+	closed any
+	isOpen bool
+}
+
+type Class struct {
+	Name    string
+	Methods map[string]*Closure
+}
+
+func (c *Class) String() string {
+	return c.Name
+}
+
+type Instance struct {
+	Class  *Class
+	Fields map[string]any
+}
+
+func (i *Instance) String() string {
+	return i.Class.Name + " instance"
+}
+
+// BoundMethod is a method read off an instance, remembering the receiver.
+type BoundMethod struct {
+	Receiver any
+	Method   *Closure
+}
+
+func (b *BoundMethod) String() string {
+	return b.Method.String()
+}
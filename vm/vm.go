@@ -0,0 +1,657 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/anwprath/glox/compiler"
+	"github.com/anwprath/glox/errors"
+	"github.com/anwprath/glox/native"
+	"github.com/anwprath/glox/object"
+	"github.com/anwprath/glox/token"
+)
+
+type callFrame struct {
+	closure *Closure
+	ip      int
+	// slots is the stack index of the frame's slot zero.
+	slots int
+	// name and callSite describe the call for stack traces.
+	name     string
+	callSite token.Span
+}
+
+// VM executes compiled chunks on a value stack.
+type VM struct {
+	stack   []any
+	frames  []callFrame
+	globals map[string]any
+	// openUpvalues holds the upvalues still pointing into the stack, sorted
+	// by slot.
+	openUpvalues []*Upvalue
+	reporter     errors.Reporter
+	// out receives the output of print statements.
+	out io.Writer
+	// ctx and budget bound the running script; steps counts the calls and
+	// loop iterations it has made so far.
+	ctx    context.Context
+	budget int
+	steps  int
+	// maxDepth bounds the number of calls in frames. allocated approximates
+	// the bytes the running script has allocated, see allocate.
+	maxDepth    int
+	memoryLimit int
+	allocated   int
+}
+
+func New(reporter errors.Reporter) *VM {
+	return &VM{
+		out:      os.Stdout,
+		stack:    make([]any, 0, 256),
+		frames:   make([]callFrame, 0, 64),
+		globals:  make(map[string]any),
+		reporter: reporter,
+		ctx:      context.Background(),
+		maxDepth: object.DefaultMaxCallDepth,
+	}
+}
+
+// SetOutput redirects print statements to w.
+func (vm *VM) SetOutput(w io.Writer) {
+	vm.out = w
+}
+
+// SetStepBudget limits each Interpret call to n steps, where a step is a
+// function call or a loop iteration. Zero means no limit.
+func (vm *VM) SetStepBudget(n int) {
+	vm.budget = n
+}
+
+// SetMaxCallDepth limits how deeply Lox calls may nest. Zero restores
+// object.DefaultMaxCallDepth.
+func (vm *VM) SetMaxCallDepth(n int) {
+	if n <= 0 {
+		n = object.DefaultMaxCallDepth
+	}
+	vm.maxDepth = n
+}
+
+// SetMemoryLimit limits the approximate number of bytes of strings, objects
+// and functions each Interpret call may allocate. Zero means no limit.
+func (vm *VM) SetMemoryLimit(bytes int) {
+	vm.memoryLimit = bytes
+}
+
+// DefineGlobal binds name to value in the global scope, replacing any
+// previous binding.
+func (vm *VM) DefineGlobal(name string, value any) {
+	vm.globals[name] = value
+}
+
+// GetGlobal returns the value bound to name in the global scope.
+func (vm *VM) GetGlobal(name string) (any, bool) {
+	value, ok := vm.globals[name]
+	return value, ok
+}
+
+// DefineNative makes fn callable from Lox as the global function name. An
+// error returned by fn is raised as a runtime error at the call site.
+func (vm *VM) DefineNative(name string, arity int, fn func(args []any) (any, error)) {
+	vm.DefineFunction(native.New(name, arity, fn))
+}
+
+// DefineFunction makes function callable from Lox under its name. What it
+// allocates through its native.Allocator counts against the memory limit.
+func (vm *VM) DefineFunction(function *native.Function) {
+	vm.globals[function.Name()] = function
+}
+
+// DefineFunc is DefineNative for an ordinary Go function, whose parameters
+// and results are converted with reflection. See native.Wrap.
+func (vm *VM) DefineFunc(name string, fn any) error {
+	function, err := native.Wrap(name, fn)
+	if err != nil {
+		return err
+	}
+	vm.DefineFunction(function)
+	return nil
+}
+
+// Interpret runs a compiled script. Globals persist between calls. Runtime
+// errors are reported and returned.
+func (vm *VM) Interpret(function *compiler.Function) error {
+	return vm.InterpretContext(context.Background(), function)
+}
+
+// InterpretContext is Interpret, but aborts with a runtime error wrapping
+// errors.ErrCanceled once ctx is done.
+func (vm *VM) InterpretContext(ctx context.Context, function *compiler.Function) error {
+	vm.ctx, vm.steps, vm.allocated = ctx, 0, 0
+	closure := &Closure{Function: function}
+	vm.push(closure)
+	vm.frames = append(vm.frames, callFrame{closure: closure, name: "<script>"})
+
+	err := vm.run()
+	if err != nil {
+		if runtimeErr, ok := err.(errors.RuntimeError); ok {
+			vm.reporter.Report(runtimeErr.Diagnostic())
+		}
+		vm.resetStack()
+	}
+	return err
+}
+
+func (vm *VM) run() error {
+	frame := &vm.frames[len(vm.frames)-1]
+	chunk := frame.closure.Function.Chunk
+
+	readByte := func() byte {
+		b := chunk.Code[frame.ip]
+		frame.ip++
+		return b
+	}
+	readShort := func() int {
+		frame.ip += 2
+		return chunk.ReadShort(frame.ip - 2)
+	}
+	readString := func() string {
+		return chunk.Constants[readShort()].(string)
+	}
+	// refresh must be called whenever the current frame changes.
+	refresh := func() {
+		frame = &vm.frames[len(vm.frames)-1]
+		chunk = frame.closure.Function.Chunk
+	}
+
+	for {
+		start := frame.ip
+		op := compiler.OpCode(readByte())
+		fail := func(message string) error {
+			return vm.runtimeError(chunk.Tokens[start], message)
+		}
+
+		switch op {
+		case compiler.OP_CONSTANT:
+			vm.push(chunk.Constants[readShort()])
+		case compiler.OP_NIL:
+			vm.push(nil)
+		case compiler.OP_TRUE:
+			vm.push(true)
+		case compiler.OP_FALSE:
+			vm.push(false)
+		case compiler.OP_POP:
+			vm.pop()
+
+		case compiler.OP_GET_LOCAL:
+			vm.push(vm.stack[frame.slots+int(readByte())])
+		case compiler.OP_SET_LOCAL:
+			vm.stack[frame.slots+int(readByte())] = vm.peek(0)
+		case compiler.OP_GET_GLOBAL:
+			name := readString()
+			value, ok := vm.globals[name]
+			if !ok {
+				return fail("Undefined variable '" + name + "'.")
+			}
+			vm.push(value)
+		case compiler.OP_DEFINE_GLOBAL:
+			vm.globals[readString()] = vm.pop()
+		case compiler.OP_SET_GLOBAL:
+			name := readString()
+			if _, ok := vm.globals[name]; !ok {
+				return fail("Undefined variable '" + name + "'.")
+			}
+			vm.globals[name] = vm.peek(0)
+		case compiler.OP_GET_UPVALUE:
+			vm.push(vm.getUpvalue(frame.closure.Upvalues[readByte()]))
+		case compiler.OP_SET_UPVALUE:
+			vm.setUpvalue(frame.closure.Upvalues[readByte()], vm.peek(0))
+
+		case compiler.OP_GET_PROPERTY:
+			name := readString()
+			if container, ok := vm.peek(0).(native.LoxObject); ok {
+				value, ok := object.GetProperty(container, name)
+				if !ok {
+					return fail("Undefined property '" + name + "'.")
+				}
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			instance, ok := vm.peek(0).(*Instance)
+			if !ok {
+				return fail("Only instances have properties.")
+			}
+			if value, ok := instance.Fields[name]; ok {
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			method, ok := instance.Class.Methods[name]
+			if !ok {
+				return fail("Undefined property '" + name + "'.")
+			}
+			vm.pop()
+			vm.push(&BoundMethod{Receiver: instance, Method: method})
+		case compiler.OP_SET_PROPERTY:
+			name := readString()
+			if object, ok := vm.peek(1).(native.LoxObject); ok {
+				value := vm.pop()
+				if err := object.Set(name, value); err != nil {
+					return fail(err.Error())
+				}
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			instance, ok := vm.peek(1).(*Instance)
+			if !ok {
+				return fail("Only instances have fields.")
+			}
+			// Only new fields grow the instance.
+			if _, ok := instance.Fields[name]; !ok {
+				if err := vm.allocate(chunk.Tokens[start], object.FieldSize); err != nil {
+					return err
+				}
+			}
+			value := vm.pop()
+			instance.Fields[name] = value
+			vm.pop()
+			vm.push(value)
+		case compiler.OP_GET_SUPER:
+			name := readString()
+			superclass := vm.pop().(*Class)
+			method, ok := superclass.Methods[name]
+			if !ok {
+				return fail("Undefined property '" + name + "'.")
+			}
+			vm.push(&BoundMethod{Receiver: vm.pop(), Method: method})
+
+		case compiler.OP_GET_INDEX:
+			index, container := vm.pop(), vm.pop()
+			value, err := object.Index(container, index)
+			if err != nil {
+				return fail(err.Error())
+			}
+			vm.push(value)
+		case compiler.OP_SET_INDEX:
+			value, index, container := vm.pop(), vm.pop(), vm.pop()
+			if object.AddsEntry(container, index) {
+				if err := vm.allocate(chunk.Tokens[start], object.EntrySize); err != nil {
+					return err
+				}
+			}
+			if err := object.SetIndex(container, index, value); err != nil {
+				return fail(err.Error())
+			}
+			vm.push(value)
+		case compiler.OP_SLICE:
+			to, from, container := vm.pop(), vm.pop(), vm.pop()
+			value, err := object.Slice(container, from, to)
+			if err != nil {
+				return fail(err.Error())
+			}
+			if err := vm.allocate(chunk.Tokens[start], object.SizeOf(value)); err != nil {
+				return err
+			}
+			vm.push(value)
+		case compiler.OP_LIST:
+			count := readShort()
+			if err := vm.allocate(chunk.Tokens[start], object.ListSize+count*object.ElementSize); err != nil {
+				return err
+			}
+			elements := slices.Clone(vm.stack[len(vm.stack)-count:])
+			vm.stack = vm.stack[:len(vm.stack)-count]
+			vm.push(object.NewList(elements))
+		case compiler.OP_MAP:
+			count := readShort()
+			if err := vm.allocate(chunk.Tokens[start], object.MapSize+count*object.EntrySize); err != nil {
+				return err
+			}
+			m := object.NewMap()
+			entries := vm.stack[len(vm.stack)-2*count:]
+			for i := 0; i < len(entries); i += 2 {
+				if err := m.Store(entries[i], entries[i+1]); err != nil {
+					return fail(err.Error())
+				}
+			}
+			vm.stack = vm.stack[:len(vm.stack)-2*count]
+			vm.push(m)
+
+		case compiler.OP_EQUAL:
+			b, a := vm.pop(), vm.pop()
+			vm.push(object.Equal(a, b))
+		case compiler.OP_NOT_EQUAL:
+			b, a := vm.pop(), vm.pop()
+			vm.push(!object.Equal(a, b))
+		case compiler.OP_ADD:
+			b, a := vm.pop(), vm.pop()
+			if l, okL := a.(float64); okL {
+				if r, okR := b.(float64); okR {
+					vm.push(l + r)
+					break
+				}
+			}
+			if l, okL := a.(string); okL {
+				if r, okR := b.(string); okR {
+					if err := vm.allocate(chunk.Tokens[start], len(l)+len(r)); err != nil {
+						return err
+					}
+					vm.push(l + r)
+					break
+				}
+			}
+			return fail("Both operands must be either string or number.")
+		case compiler.OP_GREATER, compiler.OP_GREATER_EQUAL, compiler.OP_LESS, compiler.OP_LESS_EQUAL,
+			compiler.OP_SUBTRACT, compiler.OP_MULTIPLY, compiler.OP_DIVIDE:
+			r, okR := vm.peek(0).(float64)
+			l, okL := vm.peek(1).(float64)
+			if !okL {
+				return fail("Left operand must be a number.")
+			}
+			if !okR {
+				return fail("Right operand must be a number.")
+			}
+			vm.pop()
+			vm.pop()
+			vm.push(binaryNumber(op, l, r))
+		case compiler.OP_NOT:
+			vm.push(!object.IsTruthy(vm.pop()))
+		case compiler.OP_NEGATE:
+			value, ok := vm.peek(0).(float64)
+			if !ok {
+				return fail("Operand must be a number.")
+			}
+			vm.pop()
+			vm.push(-value)
+
+		case compiler.OP_PRINT:
+			fmt.Fprintln(vm.out, object.Stringify(vm.pop()))
+
+		case compiler.OP_JUMP:
+			offset := readShort()
+			frame.ip += offset
+		case compiler.OP_JUMP_IF_FALSE:
+			offset := readShort()
+			if !object.IsTruthy(vm.peek(0)) {
+				frame.ip += offset
+			}
+		case compiler.OP_LOOP:
+			offset := readShort()
+			frame.ip -= offset
+			if err := vm.step(chunk.Tokens[start]); err != nil {
+				return err
+			}
+
+		case compiler.OP_CALL:
+			argCount := int(readByte())
+			if err := vm.step(chunk.Tokens[start]); err != nil {
+				return err
+			}
+			if err := vm.callValue(vm.peek(argCount), argCount, chunk.Tokens[start]); err != nil {
+				return err
+			}
+			refresh()
+		case compiler.OP_CLOSURE:
+			function := chunk.Constants[readShort()].(*compiler.Function)
+			if err := vm.allocate(chunk.Tokens[start], object.FunctionSize); err != nil {
+				return err
+			}
+			closure := &Closure{Function: function, Upvalues: make([]*Upvalue, function.UpvalueCount)}
+			for i := range closure.Upvalues {
+				isLocal := readByte()
+				index := int(readByte())
+				if isLocal == 1 {
+					closure.Upvalues[i] = vm.captureUpvalue(frame.slots + index)
+				} else {
+					closure.Upvalues[i] = frame.closure.Upvalues[index]
+				}
+			}
+			vm.push(closure)
+		case compiler.OP_CLOSE_UPVALUE:
+			vm.closeUpvalues(len(vm.stack) - 1)
+			vm.pop()
+		case compiler.OP_RETURN:
+			result := vm.pop()
+			vm.closeUpvalues(frame.slots)
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			if len(vm.frames) == 0 {
+				vm.pop()
+				return nil
+			}
+
+			vm.stack = vm.stack[:frame.slots]
+			vm.push(result)
+			refresh()
+
+		case compiler.OP_CLASS:
+			if err := vm.allocate(chunk.Tokens[start], object.ClassSize); err != nil {
+				return err
+			}
+			vm.push(&Class{Name: readString(), Methods: make(map[string]*Closure)})
+		case compiler.OP_INHERIT:
+			superclass, ok := vm.peek(1).(*Class)
+			if !ok {
+				return fail("Superclass must be a class.")
+			}
+			subclass := vm.peek(0).(*Class)
+			// Copy-down inheritance: classes are closed once declared, so
+			// copying the methods is equivalent to walking the chain.
+			for name, method := range superclass.Methods {
+				subclass.Methods[name] = method
+			}
+			vm.pop()
+		case compiler.OP_METHOD:
+			name := readString()
+			method := vm.peek(0).(*Closure)
+			class := vm.peek(1).(*Class)
+			class.Methods[name] = method
+			vm.pop()
+
+		default:
+			panic(fmt.Sprintf("unknown opcode %d", op))
+		}
+	}
+}
+
+func binaryNumber(op compiler.OpCode, l, r float64) any {
+	switch op {
+	case compiler.OP_GREATER:
+		return l > r
+	case compiler.OP_GREATER_EQUAL:
+		return l >= r
+	case compiler.OP_LESS:
+		return l < r
+	case compiler.OP_LESS_EQUAL:
+		return l <= r
+	case compiler.OP_SUBTRACT:
+		return l - r
+	case compiler.OP_MULTIPLY:
+		return l * r
+	case compiler.OP_DIVIDE:
+		return l / r
+	}
+	panic("unreachable code in binaryNumber")
+}
+
+func (vm *VM) callValue(callee any, argCount int, site token.Token) error {
+	switch c := callee.(type) {
+	case *Closure:
+		return vm.call(c, argCount, c.Function.Name, site)
+	case *Class:
+		if len(vm.frames) > vm.maxDepth {
+			return vm.runtimeError(site, "Stack overflow.")
+		}
+		if err := vm.allocate(site, object.InstanceSize); err != nil {
+			return err
+		}
+		vm.stack[len(vm.stack)-argCount-1] = &Instance{Class: c, Fields: make(map[string]any)}
+		if initializer, ok := c.Methods["init"]; ok {
+			return vm.call(initializer, argCount, c.Name, site)
+		}
+		if argCount != 0 {
+			return vm.runtimeError(site, fmt.Sprintf("Expected 0 arguments but got %d.", argCount))
+		}
+		return nil
+	case *BoundMethod:
+		vm.stack[len(vm.stack)-argCount-1] = c.Receiver
+		return vm.call(c.Method, argCount, c.Method.Function.Name, site)
+	case *native.Function:
+		return vm.callNative(c, argCount, site)
+	}
+	return vm.runtimeError(site, "Can only call functions and classes.")
+}
+
+func (vm *VM) call(closure *Closure, argCount int, name string, site token.Token) error {
+	if argCount != closure.Function.Arity {
+		return vm.runtimeError(site, fmt.Sprintf("Expected %d arguments but got %d.", closure.Function.Arity, argCount))
+	}
+	if len(vm.frames) > vm.maxDepth {
+		return vm.runtimeError(site, "Stack overflow.")
+	}
+
+	vm.frames = append(vm.frames, callFrame{
+		closure:  closure,
+		slots:    len(vm.stack) - argCount - 1,
+		name:     name,
+		callSite: site.Span,
+	})
+	return nil
+}
+
+func (vm *VM) callNative(function *native.Function, argCount int, site token.Token) error {
+	if argCount != function.Arity() {
+		return vm.runtimeError(site, fmt.Sprintf("Expected %d arguments but got %d.", function.Arity(), argCount))
+	}
+	if len(vm.frames) > vm.maxDepth {
+		return vm.runtimeError(site, "Stack overflow.")
+	}
+
+	// The native runs in its own frame so that it shows up in stack traces.
+	vm.frames = append(vm.frames, callFrame{name: function.Name(), callSite: site.Span})
+	args := slices.Clone(vm.stack[len(vm.stack)-argCount:])
+	result, err := function.Invoke(allocator{vm, site}, args)
+	if _, ok := err.(errors.RuntimeError); err != nil && !ok {
+		err = vm.runtimeError(site, err.Error())
+	}
+	vm.frames = vm.frames[:len(vm.frames)-1]
+	if err != nil {
+		return err
+	}
+
+	vm.stack = vm.stack[:len(vm.stack)-argCount-1]
+	vm.push(result)
+	return nil
+}
+
+func (vm *VM) captureUpvalue(slot int) *Upvalue {
+	i, found := slices.BinarySearchFunc(vm.openUpvalues, slot, func(uv *Upvalue, slot int) int {
+		return uv.slot - slot
+	})
+	if found {
+		return vm.openUpvalues[i]
+	}
+
+	upvalue := &Upvalue{slot: slot, isOpen: true}
+	vm.openUpvalues = slices.Insert(vm.openUpvalues, i, upvalue)
+	return upvalue
+}
+
+// closeUpvalues closes every open upvalue at or above slot.
+func (vm *VM) closeUpvalues(slot int) {
+	for len(vm.openUpvalues) > 0 {
+		last := vm.openUpvalues[len(vm.openUpvalues)-1]
+		if last.slot < slot {
+			return
+		}
+		last.closed = vm.stack[last.slot]
+		last.isOpen = false
+		vm.openUpvalues = vm.openUpvalues[:len(vm.openUpvalues)-1]
+	}
+}
+
+func (vm *VM) getUpvalue(upvalue *Upvalue) any {
+	if upvalue.isOpen {
+		return vm.stack[upvalue.slot]
+	}
+	return upvalue.closed
+}
+
+func (vm *VM) setUpvalue(upvalue *Upvalue, value any) {
+	if upvalue.isOpen {
+		vm.stack[upvalue.slot] = value
+	} else {
+		upvalue.closed = value
+	}
+}
+
+// step is called at every call and loop back-edge to enforce the context
+// and the step budget.
+func (vm *VM) step(at token.Token) error {
+	vm.steps++
+	if vm.budget > 0 && vm.steps > vm.budget {
+		err := vm.runtimeError(at, "Step budget exceeded.")
+		err.Cause = errors.ErrBudgetExceeded
+		return err
+	}
+	select {
+	case <-vm.ctx.Done():
+		err := vm.runtimeError(at, "Execution canceled.")
+		err.Cause = fmt.Errorf("%w: %w", errors.ErrCanceled, vm.ctx.Err())
+		return err
+	default:
+		return nil
+	}
+}
+
+// allocator charges the allocations of a native function called at at.
+type allocator struct {
+	vm *VM
+	at token.Token
+}
+
+func (a allocator) Allocate(size int) error {
+	return a.vm.allocate(a.at, size)
+}
+
+// allocate charges size bytes against the memory limit.
+func (vm *VM) allocate(at token.Token, size int) error {
+	vm.allocated += size
+	if vm.memoryLimit > 0 && vm.allocated > vm.memoryLimit {
+		err := vm.runtimeError(at, "Memory limit exceeded.")
+		err.Cause = errors.ErrMemoryLimit
+		return err
+	}
+	return nil
+}
+
+// runtimeError builds an error at t carrying the current Lox call stack.
+func (vm *VM) runtimeError(t token.Token, message string) errors.RuntimeError {
+	var trace []errors.Frame
+	for _, frame := range vm.frames[1:] {
+		trace = append(trace, errors.Frame{Function: frame.name, CallSite: frame.callSite})
+	}
+	return errors.RuntimeError{Token: t, Message: message, Trace: trace}
+}
+
+func (vm *VM) resetStack() {
+	vm.stack = vm.stack[:0]
+	vm.frames = vm.frames[:0]
+	vm.openUpvalues = vm.openUpvalues[:0]
+}
+
+func (vm *VM) push(value any) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() any {
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+func (vm *VM) peek(distance int) any {
+	return vm.stack[len(vm.stack)-1-distance]
+}